@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"gist/internal/cli/commands"
@@ -21,6 +21,11 @@ import (
 
 var version = "dev"
 
+// oauthClientID is the OAuth app used by 'gist auth login --device'. Set it
+// at build time with -ldflags "-X main.oauthClientID=..." or at run time
+// with GIST_OAUTH_CLIENT_ID.
+var oauthClientID = ""
+
 func main() {
 	if err := run(context.Background()); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -35,14 +40,32 @@ func run(ctx context.Context) error {
 	// Initialize filesystem
 	fs := storage.NewOSFileSystem()
 	requiresConfig := shouldRequireConfig(os.Args[1:])
+	profile := selectedProfile(os.Args[1:])
+	configRepo := storage.NewConfigFile(fs)
+	configRepo.SetPassphraseSource(passphrasePrompt())
+	configRepo.SetProfile(profile)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("determine working directory: %w", err)
+	}
+	settingsFiles, err := storage.NewSettingsFiles(fs, cwd)
+	if err != nil {
+		return err
+	}
+	settingsService := service.NewSettingsService(settingsFiles)
 	// Only initialize heavy services when needed
 	var gistService commands.GistService
+	var backupService commands.BackupService
+	var cacheService commands.CacheService
+	var authService commands.AuthService
 	var config *domain.Config
 	var githubClient *github.Client
-	var fileCache *cache.FileCache
+	var cacheRepo cache.Cache
+	var stagingIndex *storage.StagingIndex
+	var workCopyStore *storage.WorkingCopyStore
 
 	if requiresConfig {
-		loadedConfig, err := storage.LoadConfig(fs)
+		loadedConfig, err := storage.LoadConfig(configRepo, profile)
 		if err != nil {
 			var cfgErr domain.ErrConfigMissing
 			if errors.As(err, &cfgErr) {
@@ -51,14 +74,29 @@ func run(ctx context.Context) error {
 			return err
 		}
 		config = loadedConfig
+		if err := settingsService.ApplySettings(config); err != nil {
+			commands.PrintSettingProblems(err)
+		}
 
-		home, err := os.UserHomeDir()
+		cacheBase, err := storage.CacheBaseDir(config.Cache)
 		if err != nil {
-			return fmt.Errorf("determine home directory: %w", err)
+			return err
 		}
-		cacheDir := filepath.Join(home, ".gist-cache")
+		cacheDir := domain.ProfileCacheDir(cacheBase, config.Profile)
 		githubClient = github.NewClient(config.GitHubToken, config.GitHubUser)
-		fileCache = cache.NewFileCacheWithConfig(cacheDir, fs, config.Cache)
+		githubClient.SetBaseURL(config.APIURL)
+		githubClient.SetPerPage(config.PageSize)
+		cacheRepo, err = cache.Open(cacheDir, fs, config.Cache)
+		if err != nil {
+			return fmt.Errorf("open cache: %w", err)
+		}
+
+		stagingPath, err := storage.DefaultStagingPath()
+		if err != nil {
+			return fmt.Errorf("determine config directory: %w", err)
+		}
+		stagingIndex = storage.NewStagingIndex(fs, stagingPath)
+		workCopyStore = storage.NewWorkingCopyStore(fs)
 	}
 
 	// Initialize context for cache cleanup
@@ -66,16 +104,59 @@ func run(ctx context.Context) error {
 	defer cancel()
 
 	// Start cache cleanup when configured
-	if fileCache != nil {
-		fileCache.StartCleanup(ctx)
-		gistService = service.NewGistService(
-			githubClient, // GistRepository
-			fileCache,    // CacheRepository
-			fs,           // FileSystem
-			config,       // Config
+	if cacheRepo != nil {
+		cacheRepo.StartCleanup(ctx)
+		gists := service.NewGistService(
+			githubClient,  // GistRepository
+			cacheRepo,     // CacheRepository
+			stagingIndex,  // StagingRepository
+			workCopyStore, // WorkingCopyRepository
+			fs,            // FileSystem
+			config,        // Config
 		)
+		// Cache hits and misses are saved once, as the command exits
+		defer func() { _ = gists.FlushLookups() }()
+		gistService = gists
+		backupService = service.NewBackupService(
+			githubClient,                  // GistRepository
+			storage.NewBackupArchives(fs), // ArchiveRepository
+			config,                        // Config
+		)
+		cacheService = service.NewCacheService(cacheRepo)
+		authService = service.NewAuthService(githubClient, config)
 	}
 
+	// Profiles are managed without loading credentials
+	baseConfig := domain.NewConfig("", "")
+	_ = settingsService.ApplySettings(baseConfig) // problems are reported with the command
+	cacheBase, err := storage.CacheBaseDir(baseConfig.Cache)
+	if err != nil {
+		return err
+	}
+	profileService := service.NewProfileService(configRepo, fs, cacheBase)
+
+	// Device login needs no credentials; its token is checked with a client
+	// made for it
+	clientID := oauthClientID
+	if env := os.Getenv("GIST_OAUTH_CLIENT_ID"); env != "" {
+		clientID = env
+	}
+	loginService := service.NewLoginService(
+		func(apiURL string) service.DeviceAuthRepository {
+			oauthURL := domain.WebURL(apiURL)
+			if env := os.Getenv("GIST_OAUTH_URL"); env != "" {
+				oauthURL = env
+			}
+			return github.NewDeviceFlow(oauthURL, clientID)
+		},
+		func(apiURL, token string) service.AuthRepository {
+			client := github.NewClient(token, "")
+			client.SetBaseURL(apiURL)
+			return client
+		},
+		configRepo,
+	)
+
 	// Root command
 	rootCmd := &cobra.Command{
 		Use:   "gist",
@@ -92,21 +173,62 @@ Complete documentation is available at https://github.com/garyblankenship/gist-b
 	}
 	rootCmd.InitDefaultVersionFlag()
 
+	// The profile is read from os.Args before flag parsing, since it selects
+	// the credentials the services are built with; the flag is declared so
+	// cobra accepts and documents it
+	rootCmd.PersistentFlags().String("profile", "", "Use a named profile from ~/.gistconfig (default: current profile, or GIST_PROFILE)")
+
+	// Offline mode serves reads from the cache and never contacts GitHub
+	var offline bool
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Use cached data only; never contact GitHub")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if offline && githubClient != nil {
+			githubClient.SetOffline(true)
+		}
+	}
+
 	// Add init command
+	var tokenStore, credentialHelper, apiURL string
+	var noVerify bool
 	initCmd := &cobra.Command{
-		Use:     "init",
-		Short:   "Initialize gist configuration",
-		Long:    "Set up your GitHub configuration for gist-cli.\n\nThis command will prompt you for your GitHub username and a personal access token with 'gist' scope. The configuration will be saved locally for future use.",
-		Run:     func(cmd *cobra.Command, args []string) { setupConfiguration(fs) },
+		Use:   "init",
+		Short: "Initialize gist configuration",
+		Long:  "Set up your GitHub configuration for gist-cli.\n\nThis command will prompt you for your GitHub username and a personal access token with 'gist' scope. The configuration will be saved locally for future use.\n\nThe token is stored in ~/.gistconfig unless --token-store selects 'file', an encrypted ~/.gist-secrets unlocked with a passphrase (GIST_PASSPHRASE or a prompt), or 'helper', a git credential helper such as osxkeychain, libsecret or manager.",
+		Run: func(cmd *cobra.Command, args []string) {
+			setupConfiguration(cmd.Context(), configRepo, profile, tokenStore, credentialHelper, apiURL, !noVerify)
+		},
 		Aliases: []string{"configure"},
 	}
+	initCmd.Flags().StringVar(&tokenStore, "token-store", domain.TokenStorePlain, "Where to store the token: plain, file or helper")
+	initCmd.Flags().StringVar(&credentialHelper, "credential-helper", "", "Credential helper for --token-store helper, as in git's credential.helper")
+	initCmd.Flags().StringVar(&apiURL, "api-url", "", "GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server")
+	initCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Save the credentials without checking them against GitHub")
 	rootCmd.AddCommand(initCmd)
 
 	// Register commands
 	rootCmd.AddCommand(commands.NewPublishCommand(gistService))
 	rootCmd.AddCommand(commands.NewListCommand(gistService))
 	rootCmd.AddCommand(commands.NewShowCommand(gistService))
+	rootCmd.AddCommand(commands.NewDeleteCommand(gistService))
+	rootCmd.AddCommand(commands.NewEditCommand(gistService))
+	rootCmd.AddCommand(commands.NewUpdateCommand(gistService))
+	rootCmd.AddCommand(commands.NewAddCommand(gistService))
+	rootCmd.AddCommand(commands.NewStatusCommand(gistService))
+	rootCmd.AddCommand(commands.NewResetCommand(gistService))
+	rootCmd.AddCommand(commands.NewCommitCommand(gistService))
+	rootCmd.AddCommand(commands.NewLogCommand(gistService))
+	rootCmd.AddCommand(commands.NewDiffCommand(gistService))
+	rootCmd.AddCommand(commands.NewRestoreCommand(gistService))
+	rootCmd.AddCommand(commands.NewCloneCommand(gistService))
+	rootCmd.AddCommand(commands.NewPullCommand(gistService))
+	rootCmd.AddCommand(commands.NewPushCommand(gistService))
+	rootCmd.AddCommand(commands.NewBackupCommand(backupService))
+	rootCmd.AddCommand(commands.NewRestoreBackupCommand(backupService))
 	rootCmd.AddCommand(commands.NewSyncCommand(gistService))
+	rootCmd.AddCommand(commands.NewCacheCommand(cacheService))
+	rootCmd.AddCommand(commands.NewAuthCommand(authService, loginService))
+	rootCmd.AddCommand(commands.NewProfileCommand(profileService))
+	rootCmd.AddCommand(commands.NewConfigCommand(settingsService))
 	rootCmd.AddCommand(commands.NewTuiCommand(gistService))
 
 	// Bind the cancellable context so every command can use cmd.Context()
@@ -129,18 +251,95 @@ func shouldRequireConfig(args []string) bool {
 		}
 	}
 
-	for _, arg := range args {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--profile" {
+			i++ // skip the flag value
+			continue
+		}
 		if strings.HasPrefix(arg, "-") {
 			continue
 		}
-		return arg != "init"
+		switch arg {
+		case "init", "profile", "config":
+			return false
+		case "auth":
+			// Logging in is how credentials are set up
+			return !slices.Contains(args[i+1:], "login")
+		}
+		return true
 	}
 
 	return false
 }
 
-func setupConfiguration(fs *storage.OSFileSystem) {
-	fmt.Println("Setting up gist configuration...")
+// selectedProfile returns the profile named by a --profile flag in args,
+// falling back to GIST_PROFILE. Empty means the config file's current
+// profile.
+func selectedProfile(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--profile" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if value, ok := strings.CutPrefix(arg, "--profile="); ok {
+			return value
+		}
+	}
+	return os.Getenv("GIST_PROFILE")
+}
+
+// passphrasePrompt returns a source of the secrets file passphrase: it is
+// read from GIST_PASSPHRASE, or asked for once on the terminal
+func passphrasePrompt() func() (string, error) {
+	var passphrase string
+	return func() (string, error) {
+		if passphrase != "" {
+			return passphrase, nil
+		}
+		if env, err := storage.EnvPassphrase(); err == nil {
+			passphrase = env
+			return passphrase, nil
+		}
+		if !term.IsTerminal(os.Stdin.Fd()) {
+			return "", domain.ErrConfigMissing{Field: "GIST_PASSPHRASE"}
+		}
+
+		fmt.Fprint(os.Stderr, "Passphrase for ~/.gist-secrets: ")
+		input, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase: %w", err)
+		}
+		if len(input) == 0 {
+			return "", domain.ErrConfigMissing{Field: "passphrase"}
+		}
+		passphrase = string(input)
+		return passphrase, nil
+	}
+}
+
+func setupConfiguration(ctx context.Context, configRepo *storage.ConfigFile, profile, tokenStore, credentialHelper, apiURL string, verify bool) {
+	if profile == "" {
+		profile = domain.DefaultProfile
+	}
+	if !domain.ValidProfileName(profile) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", domain.ErrInvalidProfile{Name: profile})
+		os.Exit(1)
+	}
+	if err := domain.CheckTokenStore(tokenStore, credentialHelper); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	apiURL, err := domain.NormalizeAPIURL(apiURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Setting up gist configuration for profile %s...\n", profile)
 	fmt.Println()
 
 	var username, token string
@@ -162,9 +361,26 @@ func setupConfiguration(fs *storage.OSFileSystem) {
 		os.Exit(1)
 	}
 
-	configRepo := storage.NewConfigFile(fs)
-
 	config := domain.NewConfig(username, token)
+	config.Profile = profile
+	config.TokenStore = tokenStore
+	config.CredentialHelper = credentialHelper
+	config.APIURL = apiURL
+
+	if verify {
+		fmt.Println("Checking the token with GitHub...")
+		client := github.NewClient(config.GitHubToken, config.GitHubUser)
+		client.SetBaseURL(config.APIURL)
+		status, err := service.NewAuthService(client, config).AuthStatus(ctx)
+		if status != nil {
+			commands.PrintAuthStatus(status)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Nothing was saved; fix the credentials, or rerun with --no-verify to save them anyway")
+			os.Exit(1)
+		}
+	}
 
 	if err := configRepo.Save(config); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving configuration: %v\n", err)
@@ -172,5 +388,13 @@ func setupConfiguration(fs *storage.OSFileSystem) {
 	}
 
 	fmt.Println("✓ Configuration saved successfully")
+
+	profiles, _ := configRepo.ListProfiles()
+	for _, p := range profiles {
+		if p.Name == profile && !p.Current {
+			fmt.Printf("Use it with --profile %s, or make it current with 'gist profile use %s'\n", profile, profile)
+			return
+		}
+	}
 	fmt.Println("You can now use gist commands")
 }
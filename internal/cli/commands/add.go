@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// AddCommand handles the 'add' command to stage files
+type AddCommand struct {
+	service GistService
+}
+
+// NewAddCommand creates a new add command
+func NewAddCommand(service GistService) *cobra.Command {
+	ac := &AddCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "add <files...>",
+		Short: "Stage files for the next commit",
+		Long: `Record a snapshot of files in the staging area.
+
+Staged files are published with 'gist commit'. Adding a file again
+replaces its snapshot with the current contents.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: ac.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the add command
+func (c *AddCommand) Run(cmd *cobra.Command, args []string) error {
+	staged, err := c.service.StageFiles(args)
+	if err != nil {
+		return fmt.Errorf("add failed: %w", err)
+	}
+
+	for _, file := range staged {
+		fmt.Printf("  staged %s\n", file.Filename)
+	}
+	return nil
+}
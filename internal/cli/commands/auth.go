@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// AuthCommand handles the 'auth' command group to inspect the configured
+// GitHub credentials
+type AuthCommand struct {
+	service          AuthService
+	login            LoginService
+	device           bool
+	tokenStore       string
+	credentialHelper string
+	apiURL           string
+}
+
+// NewAuthCommand creates a new auth command with its subcommands
+func NewAuthCommand(service AuthService, login LoginService) *cobra.Command {
+	ac := &AuthCommand{service: service, login: login}
+
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect GitHub authentication",
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Check the configured token",
+		Long: `Check the configured token against GitHub: that the API URL serves the
+GitHub API, that GitHub accepts the token, that it belongs to the configured
+user and that it has the 'gist' scope. Also shows the API rate limit left.
+
+Fine-grained tokens do not report their permissions, so their gist access
+cannot be checked.`,
+		Args: cobra.NoArgs,
+		RunE: ac.RunStatus,
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login --device",
+		Short: "Sign in to GitHub in the browser",
+		Long: `Sign in with GitHub's device flow instead of pasting a personal access
+token: gist shows a code to enter at github.com/login/device, waits for you
+to authorize it and saves the token with your GitHub username.
+
+The token is saved to the profile selected with --profile, in the token store
+chosen as with 'gist init'.`,
+		Args: cobra.NoArgs,
+		RunE: ac.RunLogin,
+	}
+	loginCmd.Flags().BoolVar(&ac.device, "device", false, "Use the OAuth device flow")
+	loginCmd.Flags().StringVar(&ac.tokenStore, "token-store", domain.TokenStorePlain, "Where to store the token: plain, file or helper")
+	loginCmd.Flags().StringVar(&ac.apiURL, "api-url", "", "GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server")
+	loginCmd.Flags().StringVar(&ac.credentialHelper, "credential-helper", "", "Credential helper for --token-store helper, as in git's credential.helper")
+
+	cmd.AddCommand(statusCmd, loginCmd)
+
+	return cmd
+}
+
+// RunStatus executes the auth status command
+func (c *AuthCommand) RunStatus(cmd *cobra.Command, args []string) error {
+	status, err := c.service.AuthStatus(cmd.Context())
+	if status != nil {
+		PrintAuthStatus(status)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("✓ Token is valid")
+	return nil
+}
+
+// RunLogin executes the auth login command
+func (c *AuthCommand) RunLogin(cmd *cobra.Command, args []string) error {
+	if !c.device {
+		return fmt.Errorf("only --device login is supported; run 'gist init' to paste a personal access token")
+	}
+
+	config := domain.NewConfig("", "")
+	config.TokenStore = c.tokenStore
+	config.CredentialHelper = c.credentialHelper
+	config.APIURL = c.apiURL
+
+	status, err := c.login.DeviceLogin(cmd.Context(), config, func(code *domain.DeviceCode) {
+		fmt.Printf("Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+		fmt.Println("Waiting for authorization...")
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Logged in as %s\n", status.Login)
+	return nil
+}
+
+// PrintAuthStatus shows what GitHub reported about a token
+func PrintAuthStatus(status *domain.AuthStatus) {
+	scopes := "unknown (fine-grained token)"
+	if status.ScopesKnown {
+		scopes = strings.Join(status.Scopes, ", ")
+		if scopes == "" {
+			scopes = "none"
+		}
+	}
+
+	api := status.APIURL
+	if status.Enterprise != "" {
+		api += " (GitHub Enterprise Server " + status.Enterprise + ")"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "API:\t%s\n", api)
+	fmt.Fprintf(w, "Logged in as:\t%s\n", status.Login)
+	fmt.Fprintf(w, "Token scopes:\t%s\n", scopes)
+	if !status.RateLimitReset.IsZero() {
+		fmt.Fprintf(w, "Rate limit:\t%d/%d left, resets in %s\n",
+			status.RateLimitRemaining, status.RateLimitLimit,
+			time.Until(status.RateLimitReset).Round(time.Second))
+	}
+	w.Flush()
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupCommand handles the 'backup' command to archive every gist
+type BackupCommand struct {
+	service BackupService
+	out     string
+}
+
+// NewBackupCommand creates a new backup command
+func NewBackupCommand(service BackupService) *cobra.Command {
+	bc := &BackupCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "backup --out <archive.tar.gz|dir>",
+		Short: "Back up every gist to an archive",
+		Long: `Download every gist of your account, with full file contents, into a
+gzip-compressed tarball (when --out ends in .tar.gz or .tgz) or a new
+directory. A manifest.json records each gist's metadata.
+
+Restore with 'gist restore-backup'.`,
+		Args: cobra.NoArgs,
+		RunE: bc.Run,
+	}
+
+	cmd.Flags().StringVarP(&bc.out, "out", "o", "", "Archive or directory to create (required)")
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+// Run executes the backup command
+func (c *BackupCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	fmt.Println("Backing up gists from GitHub...")
+
+	manifest, err := c.service.BackupGists(ctx, c.out)
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	files := 0
+	for _, entry := range manifest.Gists {
+		files += len(entry.Files)
+	}
+
+	fmt.Printf("✓ Backed up %d gist(s), %d file(s) to %s\n", len(manifest.Gists), files, c.out)
+	return nil
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// CacheCommand handles the 'cache' command group to inspect and maintain
+// the local gist cache
+type CacheCommand struct {
+	service   CacheService
+	olderThan time.Duration
+}
+
+// NewCacheCommand creates a new cache command with its subcommands
+func NewCacheCommand(service CacheService) *cobra.Command {
+	cc := &CacheCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and maintain the local cache",
+		Long: `Inspect and maintain the local gist cache.
+
+The cache lives in ~/.gist-cache unless GIST_CACHE_DIR is set; profiles other
+than the default one cache in its profiles/<name> subdirectory. Everything in
+it can be refetched from GitHub, so pruning or clearing it is always safe.`,
+	}
+
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show what the cache holds",
+		Long: `Show the number of cached gists, the cache size and age, and the cache
+hits and misses since the previous 'gist cache stats'.`,
+		Args: cobra.NoArgs,
+		RunE: cc.RunStats,
+	}
+
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List cached gists",
+		Long: `List the gists in the cached list and the fully fetched gists in the
+detail cache, with the age and size of each entry.`,
+		Args: cobra.NoArgs,
+		RunE: cc.RunList,
+	}
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune --older-than <duration>",
+		Short: "Remove old cache entries",
+		Long: `Remove the cache entries fetched longer ago than --older-than, e.g. 24h
+or 90m. The gist list is dropped as a whole when it is that old.`,
+		Args: cobra.NoArgs,
+		RunE: cc.RunPrune,
+	}
+	pruneCmd.Flags().DurationVar(&cc.olderThan, "older-than", 0, "Remove entries fetched longer ago than this")
+	_ = pruneCmd.MarkFlagRequired("older-than")
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached data",
+		Args:  cobra.NoArgs,
+		RunE:  cc.RunClear,
+	}
+
+	cmd.AddCommand(statsCmd, lsCmd, pruneCmd, clearCmd)
+
+	return cmd
+}
+
+// RunStats executes the cache stats command
+func (c *CacheCommand) RunStats(cmd *cobra.Command, args []string) error {
+	stats, err := c.service.CacheStats()
+	if err != nil {
+		return fmt.Errorf("cache stats: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Directory:\t%s\n", stats.Dir)
+	fmt.Fprintf(w, "Backend:\t%s\n", stats.Backend)
+	fmt.Fprintf(w, "Listed gists:\t%d\n", stats.Listed)
+	fmt.Fprintf(w, "Fetched gists:\t%d\n", stats.Details)
+	fmt.Fprintf(w, "Size:\t%s\n", formatSize(stats.Size))
+	fmt.Fprintf(w, "List fetched:\t%s\n", formatAge(stats.FetchedAt))
+	fmt.Fprintf(w, "Last full sync:\t%s\n", formatAge(stats.FullSyncAt))
+	fmt.Fprintf(w, "Oldest fetched gist:\t%s\n", formatAge(stats.OldestDetail))
+	fmt.Fprintf(w, "Hits / misses:\t%d / %d (since %s)\n",
+		stats.Hits, stats.Misses, stats.CountingSince.Local().Format("2006-01-02 15:04"))
+	w.Flush()
+
+	return nil
+}
+
+// RunList executes the cache ls command
+func (c *CacheCommand) RunList(cmd *cobra.Command, args []string) error {
+	entries, err := c.service.CacheEntries()
+	if err != nil {
+		return fmt.Errorf("list cache: %w", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Cache is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tFETCHED\tSIZE\tDESCRIPTION")
+
+	for _, entry := range entries {
+		kind := "list"
+		if entry.Detail {
+			kind = "gist"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			shortID(string(entry.ID)),
+			kind,
+			formatAge(entry.FetchedAt),
+			formatSize(entry.Size),
+			describeGist(&domain.Gist{Description: entry.Description}),
+		)
+	}
+
+	w.Flush()
+	return nil
+}
+
+// RunPrune executes the cache prune command
+func (c *CacheCommand) RunPrune(cmd *cobra.Command, args []string) error {
+	removed, err := c.service.PruneCache(c.olderThan)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Pruned %d cache entries older than %s\n", removed, c.olderThan)
+	return nil
+}
+
+// RunClear executes the cache clear command
+func (c *CacheCommand) RunClear(cmd *cobra.Command, args []string) error {
+	if err := c.service.ClearCache(); err != nil {
+		return err
+	}
+
+	fmt.Println("✓ Cache cleared")
+	return nil
+}
+
+// formatSize renders a byte count for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// formatAge renders how long ago t was, or "never" for a zero time
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
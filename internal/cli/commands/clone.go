@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// CloneCommand handles the 'clone' command to mirror a gist locally
+type CloneCommand struct {
+	service GistService
+}
+
+// NewCloneCommand creates a new clone command
+func NewCloneCommand(service GistService) *cobra.Command {
+	cc := &CloneCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "clone <gist-id> [dir]",
+		Short: "Copy a gist into a local directory",
+		Long: `Write every file of a gist into a directory (the gist ID by default),
+together with a .gist.json file recording which gist it mirrors.
+
+Use 'gist pull' inside the directory to fetch later changes.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: cc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the clone command
+func (c *CloneCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	gist, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	dir := string(gist.ID)
+	if len(args) > 1 {
+		dir = args[1]
+	}
+
+	wc, err := c.service.CloneGist(ctx, string(gist.ID), dir)
+	if err != nil {
+		return fmt.Errorf("clone failed: %w", err)
+	}
+
+	var filenames []string
+	for name := range wc.Files {
+		filenames = append(filenames, name)
+	}
+	sort.Strings(filenames)
+	for _, name := range filenames {
+		fmt.Printf("  %s\n", name)
+	}
+
+	fmt.Printf("✓ Cloned gist %s into %s\n", wc.ID, dir)
+	return nil
+}
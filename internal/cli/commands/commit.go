@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// CommitCommand handles the 'commit' command to publish staged files
+type CommitCommand struct {
+	service     GistService
+	description string
+	public      bool
+	private     bool
+	target      string
+}
+
+// NewCommitCommand creates a new commit command
+func NewCommitCommand(service GistService) *cobra.Command {
+	cc := &CommitCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Publish staged files as a gist",
+		Long: `Create a gist from the staged files, or update an existing gist with --to.
+
+When updating, staged files replace files of the same name and new files
+are added. A --desc given with --to replaces the description while keeping
+its hashtags. The staging area is cleared after a successful commit.`,
+		Args: cobra.NoArgs,
+		RunE: cc.Run,
+	}
+
+	cmd.Flags().StringVarP(&cc.description, "desc", "d", "", "Set description for the gist")
+	cmd.Flags().BoolVarP(&cc.public, "public", "p", false, "Make a new gist public (default: defaults.visibility setting)")
+	cmd.Flags().BoolVar(&cc.private, "private", false, "Make a new gist private")
+	cmd.MarkFlagsMutuallyExclusive("public", "private")
+	cmd.Flags().StringVar(&cc.target, "to", "", "Update this gist ID (or prefix) instead of creating one")
+
+	return cmd
+}
+
+// Run executes the commit command
+func (c *CommitCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	targetID := ""
+	if c.target != "" {
+		gist, err := resolveGist(ctx, c.service, c.target)
+		if err != nil {
+			return err
+		}
+		targetID = string(gist.ID)
+	}
+
+	public := newGistPublic(c.public, c.private, c.service.Preferences())
+	gistID, err := c.service.CommitStaged(ctx, c.description, public, targetID)
+	if err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	if targetID != "" {
+		fmt.Printf("✓ Updated gist: %s\n", gistID)
+	} else {
+		fmt.Printf("✓ Created gist: %s\n", gistID)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCommand handles the 'config' command group to read and change the
+// layered settings
+type ConfigCommand struct {
+	service SettingsService
+	project bool
+}
+
+// NewConfigCommand creates a new config command with its subcommands
+func NewConfigCommand(service SettingsService) *cobra.Command {
+	cc := &ConfigCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change settings",
+		Long: `Read and change the settings of gist.
+
+Settings are YAML files: yours in gist/config.yaml under your config
+directory (~/.config on Linux), and a project's .gist.yaml in the working
+directory or one of its parents. A setting is taken from, in order of
+precedence: command-line flags, its GIST_* environment variable, the
+project file, your file, then the default.
+
+A project file may only set output, defaults.visibility, defaults.tags and
+cache.ttl, so a repository you check out cannot pick your editor, cache
+directory or API URL.
+
+Credentials and profiles are kept apart in ~/.gistconfig; see 'gist init'.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every setting with its value and source",
+		Args:  cobra.NoArgs,
+		RunE:  cc.RunList,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cc.RunGet,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting in your settings file",
+		Args:  cobra.ExactArgs(2),
+		RunE:  cc.RunSet,
+	}
+	setCmd.Flags().BoolVar(&cc.project, "project", false, "Write to the project's .gist.yaml instead")
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open your settings file in your editor",
+		Args:  cobra.NoArgs,
+		RunE:  cc.RunEdit,
+	}
+	editCmd.Flags().BoolVar(&cc.project, "project", false, "Edit the project's .gist.yaml instead")
+
+	cmd.AddCommand(listCmd, getCmd, setCmd, editCmd)
+
+	return cmd
+}
+
+// RunList executes the config list command
+func (c *ConfigCommand) RunList(cmd *cobra.Command, args []string) error {
+	settings, problems := c.service.Settings()
+	if settings == nil {
+		return problems
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tDESCRIPTION")
+	for _, setting := range settings {
+		source := setting.Source
+		if source == domain.SourceEnv {
+			source = setting.Key.Env
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", setting.Key.Name, setting.Value, source, setting.Key.Doc)
+	}
+	w.Flush()
+
+	for _, project := range []bool{false, true} {
+		if path, err := c.service.SettingsPath(project); err == nil {
+			name := "User"
+			if project {
+				name = "Project"
+			}
+			state := ""
+			if _, err := os.Stat(path); err != nil {
+				state = " (not created)"
+			}
+			fmt.Printf("\n%s settings: %s%s", name, path, state)
+		}
+	}
+	fmt.Println()
+
+	PrintSettingProblems(problems)
+	return nil
+}
+
+// RunGet executes the config get command
+func (c *ConfigCommand) RunGet(cmd *cobra.Command, args []string) error {
+	setting, err := c.service.GetSetting(args[0])
+	if setting == nil {
+		return err
+	}
+
+	fmt.Println(setting.Value)
+	return nil
+}
+
+// RunSet executes the config set command
+func (c *ConfigCommand) RunSet(cmd *cobra.Command, args []string) error {
+	if err := c.service.SetSetting(args[0], args[1], c.project); err != nil {
+		return err
+	}
+
+	path, _ := c.service.SettingsPath(c.project)
+	fmt.Printf("✓ Set %s to %q in %s\n", args[0], args[1], path)
+	return nil
+}
+
+// RunEdit executes the config edit command
+func (c *ConfigCommand) RunEdit(cmd *cobra.Command, args []string) error {
+	path, err := c.service.SettingsPath(c.project)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create settings directory: %w", err)
+	}
+
+	editor := ""
+	if setting, _ := c.service.GetSetting("editor"); setting != nil {
+		editor = setting.Value
+	}
+	if err := runEditor(editor, filepath.Dir(path), []string{path}); err != nil {
+		return err
+	}
+
+	if err := c.service.CheckSettings(c.project); err != nil {
+		again := "gist config edit"
+		if c.project {
+			again += " --project"
+		}
+		return fmt.Errorf("%s has problems; run '%s' again to fix them:\n%w", path, again, err)
+	}
+
+	fmt.Printf("✓ Settings in %s are valid\n", path)
+	return nil
+}
+
+// PrintSettingProblems warns about settings entries that were skipped
+// while loading the settings
+func PrintSettingProblems(err error) {
+	if err == nil {
+		return
+	}
+
+	problems := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		problems = joined.Unwrap()
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring setting: %v\n", problem)
+	}
+}
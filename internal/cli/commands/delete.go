@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// DeleteCommand handles the 'delete' command to remove gists
+type DeleteCommand struct {
+	service GistService
+	yes     bool
+	dryRun  bool
+}
+
+// NewDeleteCommand creates a new delete command
+func NewDeleteCommand(service GistService) *cobra.Command {
+	dc := &DeleteCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:     "delete <gist-id>...",
+		Aliases: []string{"rm"},
+		Short:   "Delete gists",
+		Long: `Delete one or more gists from your GitHub account.
+
+Each gist ID can be the full ID or a prefix, as with 'gist show'.
+You will be asked to confirm unless --yes is given. Use --dry-run to
+list what would be deleted without deleting anything.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: dc.Run,
+	}
+
+	cmd.Flags().BoolVarP(&dc.yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVarP(&dc.dryRun, "dry-run", "n", false, "Show what would be deleted without deleting")
+
+	return cmd
+}
+
+// Run executes the delete command
+func (c *DeleteCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	// Resolve every ID before deleting anything so a typo aborts the batch
+	var gists []*domain.Gist
+	seen := make(map[domain.GistID]bool)
+	for _, arg := range args {
+		gist, err := resolveGist(ctx, c.service, arg)
+		if err != nil {
+			return err
+		}
+		if seen[gist.ID] {
+			continue
+		}
+		seen[gist.ID] = true
+		gists = append(gists, gist)
+	}
+
+	if c.dryRun {
+		fmt.Printf("Would delete %d gist(s):\n", len(gists))
+	} else {
+		fmt.Printf("About to delete %d gist(s):\n", len(gists))
+	}
+	for _, gist := range gists {
+		fmt.Printf("  %s  %s\n", gist.ID, describeGist(gist))
+	}
+
+	if c.dryRun {
+		return nil
+	}
+
+	if !c.yes && !confirm(cmd.InOrStdin(), "Delete these gists? [y/N]: ") {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	var failed []string
+	for _, gist := range gists {
+		if err := c.service.DeleteGist(ctx, string(gist.ID)); err != nil {
+			fmt.Printf("✗ %s: %v\n", gist.ID, err)
+			failed = append(failed, string(gist.ID))
+			continue
+		}
+		fmt.Printf("✓ Deleted gist: %s\n", gist.ID)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d gist(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// describeGist returns the description of a gist, or a placeholder
+func describeGist(gist *domain.Gist) string {
+	if gist.Description == "" {
+		return "(no description)"
+	}
+	return gist.Description
+}
+
+// confirm prints a prompt and reports whether the user answered yes
+func confirm(in io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
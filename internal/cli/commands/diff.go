@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"gist/internal/diff"
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// DiffCommand handles the 'diff' command to compare gist revisions
+type DiffCommand struct {
+	service GistService
+}
+
+// NewDiffCommand creates a new diff command
+func NewDiffCommand(service GistService) *cobra.Command {
+	dc := &DiffCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "diff <gist-id> [rev1] [rev2]",
+		Short: "Show changes between gist revisions",
+		Long: `Print a unified diff per file between two revisions of a gist.
+
+With no revisions, the latest revision is compared with the one before it.
+With one revision, that revision is compared with the latest. Revisions are
+listed by 'gist log' and may be abbreviated to a unique prefix.`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: dc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the diff command
+func (c *DiffCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	gist, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	var from, to string
+	if len(args) > 1 {
+		from = args[1]
+	}
+	if len(args) > 2 {
+		to = args[2]
+	}
+
+	oldGist, newGist, err := c.service.DiffRevisions(ctx, string(gist.ID), from, to)
+	if err != nil {
+		return fmt.Errorf("diff failed: %w", err)
+	}
+
+	output := diffGists(oldGist, newGist)
+	if output == "" {
+		fmt.Println("No differences")
+		return nil
+	}
+
+	fmt.Print(output)
+	return nil
+}
+
+// diffGists renders a unified diff for every file that differs between two
+// versions of a gist, in filename order
+func diffGists(oldGist, newGist *domain.Gist) string {
+	names := make(map[string]bool)
+	for name := range oldGist.Files {
+		names[name] = true
+	}
+	for name := range newGist.Files {
+		names[name] = true
+	}
+
+	var filenames []string
+	for name := range names {
+		filenames = append(filenames, name)
+	}
+	sort.Strings(filenames)
+
+	var output string
+	for _, name := range filenames {
+		oldFile, inOld := oldGist.Files[name]
+		newFile, inNew := newGist.Files[name]
+
+		oldName, newName := "a/"+name, "b/"+name
+		if !inOld {
+			oldName = "/dev/null"
+		}
+		if !inNew {
+			newName = "/dev/null"
+		}
+
+		output += diff.Unified(oldName, newName, diff.Lines(oldFile.Content, newFile.Content), diffContext)
+	}
+
+	return output
+}
@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// EditCommand handles the 'edit' command to modify a gist in $EDITOR
+type EditCommand struct {
+	service GistService
+}
+
+// NewEditCommand creates a new edit command
+func NewEditCommand(service GistService) *cobra.Command {
+	ec := &EditCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "edit <gist-id>",
+		Short: "Edit a gist in your editor",
+		Long: `Open the files of a gist in your editor and push the changes back.
+
+The files are written to a temporary directory and opened with the editor
+setting, $VISUAL, $EDITOR or vi. When the editor exits, modified files and any new files
+created in the directory are sent to GitHub in a single update. Files you
+delete are removed from the gist, and a file renamed without changes is
+sent as a rename.
+
+The gist ID can be the full ID or a prefix (e.g., "a1b2c3d4" or "a1b2").`,
+		Args: cobra.ExactArgs(1),
+		RunE: ec.Run,
+	}
+
+	return cmd
+}
+
+// fileChanges describes how an edited working directory differs from a gist
+type fileChanges struct {
+	added    []string
+	modified []string
+	removed  []string
+	renamed  map[string]string
+	files    map[string]domain.GistFile
+}
+
+func (fc fileChanges) empty() bool {
+	return len(fc.files) == 0
+}
+
+// Run executes the edit command
+func (c *EditCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	match, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	// List entries may omit file contents; always edit the full gist
+	gist, err := c.service.GetGist(ctx, string(match.ID))
+	if err != nil {
+		return fmt.Errorf("get gist: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "gist-edit-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	paths, err := writeGistFiles(dir, gist)
+	if err != nil {
+		return err
+	}
+
+	if err := runEditor(c.service.Preferences().Editor, dir, paths); err != nil {
+		return err
+	}
+
+	changes, err := diffGistDir(dir, gist)
+	if err != nil {
+		return err
+	}
+
+	if changes.empty() {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	printChanges(changes)
+
+	update := &domain.Gist{
+		ID:          gist.ID,
+		Description: gist.Description,
+		Files:       changes.files,
+	}
+	if err := c.service.UpdateGist(ctx, update); err != nil {
+		return fmt.Errorf("edit failed: %w", err)
+	}
+
+	fmt.Printf("✓ Updated gist: %s\n", gist.ID)
+	return nil
+}
+
+// writeGistFiles writes each gist file into dir and returns the paths in
+// filename order
+func writeGistFiles(dir string, gist *domain.Gist) ([]string, error) {
+	var filenames []string
+	for name := range gist.Files {
+		filenames = append(filenames, name)
+	}
+	sort.Strings(filenames)
+
+	var paths []string
+	for _, name := range filenames {
+		// Gist filenames cannot contain path separators, but never trust that
+		path := filepath.Join(dir, filepath.Base(name))
+		if err := os.WriteFile(path, []byte(gist.Files[name].Content), 0600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// editorCommand returns the user's preferred editor command line: the
+// editor setting, else $VISUAL or $EDITOR, else vi
+func editorCommand(configured string) []string {
+	if fields := strings.Fields(configured); len(fields) > 0 {
+		return fields
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// runEditor opens paths in the user's editor from dir and waits for it to exit
+func runEditor(configured, dir string, paths []string) error {
+	editor := editorCommand(configured)
+	editorCmd := exec.Command(editor[0], append(editor[1:], paths...)...)
+	editorCmd.Dir = dir
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("run editor %s: %w", editor[0], err)
+	}
+	return nil
+}
+
+// diffGistDir compares the files in dir against gist. Hidden files and
+// editor backups (name~) are ignored so swap files are never uploaded.
+func diffGistDir(dir string, gist *domain.Gist) (fileChanges, error) {
+	changes := fileChanges{
+		renamed: make(map[string]string),
+		files:   make(map[string]domain.GistFile),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return changes, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	present := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		present[name] = true
+
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return changes, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		original, exists := gist.Files[name]
+		switch {
+		case !exists:
+			changes.added = append(changes.added, name)
+		case !bytes.Equal(content, []byte(original.Content)):
+			changes.modified = append(changes.modified, name)
+		default:
+			continue
+		}
+		changes.files[name] = domain.GistFile{Filename: name, Content: string(content)}
+	}
+
+	for name := range gist.Files {
+		if !present[filepath.Base(name)] {
+			changes.removed = append(changes.removed, name)
+		}
+	}
+
+	sort.Strings(changes.added)
+	sort.Strings(changes.modified)
+	sort.Strings(changes.removed)
+
+	// A removed file whose content reappears unchanged under a new name is a
+	// rename, which keeps the file's history on GitHub
+	var added, removed []string
+	for _, oldName := range changes.removed {
+		newName := ""
+		for _, candidate := range changes.added {
+			if file, ok := changes.files[candidate]; ok && file.Content == gist.Files[oldName].Content {
+				newName = candidate
+				break
+			}
+		}
+		if newName == "" {
+			removed = append(removed, oldName)
+			changes.files[oldName] = domain.GistFile{Filename: oldName, Deleted: true}
+			continue
+		}
+		changes.renamed[oldName] = newName
+		delete(changes.files, newName)
+		changes.files[oldName] = domain.GistFile{Filename: newName}
+	}
+	for _, name := range changes.added {
+		if _, ok := changes.files[name]; ok {
+			added = append(added, name)
+		}
+	}
+	changes.added = added
+	changes.removed = removed
+
+	return changes, nil
+}
+
+// printChanges lists added, modified, renamed and removed files
+func printChanges(changes fileChanges) {
+	for _, name := range changes.added {
+		fmt.Printf("  A %s\n", name)
+	}
+	for _, name := range changes.modified {
+		fmt.Printf("  M %s\n", name)
+	}
+	var oldNames []string
+	for oldName := range changes.renamed {
+		oldNames = append(oldNames, oldName)
+	}
+	sort.Strings(oldNames)
+	for _, oldName := range oldNames {
+		fmt.Printf("  R %s -> %s\n", oldName, changes.renamed[oldName])
+	}
+	for _, name := range changes.removed {
+		fmt.Printf("  D %s\n", name)
+	}
+}
@@ -16,6 +16,7 @@ type ListCommand struct {
 	service  GistService
 	tag      string
 	showTags bool
+	output   string
 }
 
 // NewListCommand creates a new list command
@@ -33,6 +34,7 @@ Use --tag to filter by specific tags or --tags to show all available tags.`,
 
 	cmd.Flags().StringVarP(&lc.tag, "tag", "t", "", "Filter by tag")
 	cmd.Flags().BoolVar(&lc.showTags, "tags", false, "Show all available tags")
+	cmd.Flags().StringVarP(&lc.output, "output", "o", "", outputUsage)
 
 	return cmd
 }
@@ -41,13 +43,18 @@ Use --tag to filter by specific tags or --tags to show all available tags.`,
 func (c *ListCommand) Run(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	format, err := outputFormat(c.output, c.service.Preferences())
+	if err != nil {
+		return err
+	}
+
 	// Get all gists
 	gists, err := c.service.ListGists(ctx)
 	if err != nil {
 		return fmt.Errorf("list gists: %w", err)
 	}
 
-	if len(gists) == 0 {
+	if len(gists) == 0 && format == domain.OutputTable {
 		fmt.Println("No gists found")
 		fmt.Println("Create your first gist with 'gist publish <file>'")
 		return nil
@@ -62,7 +69,7 @@ func (c *ListCommand) Run(cmd *cobra.Command, args []string) error {
 	// Filter by tag if specified
 	if c.tag != "" {
 		gists = c.filterByTag(gists, c.tag)
-		if len(gists) == 0 {
+		if len(gists) == 0 && format == domain.OutputTable {
 			fmt.Printf("No gists found with tag #%s\n", c.tag)
 			return nil
 		}
@@ -73,6 +80,13 @@ func (c *ListCommand) Run(cmd *cobra.Command, args []string) error {
 		return gists[i].CreatedAt.After(gists[j].CreatedAt)
 	})
 
+	if format == domain.OutputJSON {
+		if gists == nil {
+			gists = []domain.Gist{}
+		}
+		return printJSON(gists)
+	}
+
 	// Display gists
 	c.displayGists(gists)
 
@@ -131,7 +145,7 @@ func (c *ListCommand) displayTags(gists []domain.Gist) {
 	tagMap := make(map[string]int)
 
 	for _, gist := range gists {
-		tags := extractTags(gist.Description)
+		tags := domain.ExtractTags(gist.Description)
 		for _, tag := range tags {
 			tagMap[tag]++
 		}
@@ -160,7 +174,7 @@ func (c *ListCommand) filterByTag(gists []domain.Gist, tag string) []domain.Gist
 	var filtered []domain.Gist
 
 	for _, gist := range gists {
-		tags := extractTags(gist.Description)
+		tags := domain.ExtractTags(gist.Description)
 		for _, t := range tags {
 			if strings.EqualFold(t, tag) {
 				filtered = append(filtered, gist)
@@ -171,22 +185,3 @@ func (c *ListCommand) filterByTag(gists []domain.Gist, tag string) []domain.Gist
 
 	return filtered
 }
-
-// extractTags extracts hashtags from a description
-func extractTags(description string) []string {
-	var tags []string
-	words := strings.Fields(description)
-
-	for _, word := range words {
-		if strings.HasPrefix(word, "#") && len(word) > 1 {
-			tag := strings.TrimPrefix(word, "#")
-			// Remove trailing punctuation
-			tag = strings.TrimRight(tag, ".,!?;:")
-			if tag != "" {
-				tags = append(tags, tag)
-			}
-		}
-	}
-
-	return tags
-}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// LogCommand handles the 'log' command to list gist revisions
+type LogCommand struct {
+	service GistService
+}
+
+// NewLogCommand creates a new log command
+func NewLogCommand(service GistService) *cobra.Command {
+	lc := &LogCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "log <gist-id>",
+		Short: "Show gist revision history",
+		Long: `List the revisions of a gist, newest first, with the lines added and
+removed by each one.
+
+Revision IDs (or a unique prefix) can be passed to 'gist diff'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: lc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the log command
+func (c *LogCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	gist, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	history, err := c.service.GistHistory(ctx, string(gist.ID))
+	if err != nil {
+		return fmt.Errorf("log failed: %w", err)
+	}
+
+	if len(history) == 0 {
+		fmt.Println("No revisions found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tDATE\tCHANGES")
+
+	for i, rev := range history {
+		changes := fmt.Sprintf("+%d -%d", rev.ChangeStatus.Additions, rev.ChangeStatus.Deletions)
+		if i == 0 {
+			changes += " (current)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			shortID(rev.Version),
+			rev.CommittedAt.Local().Format("2006-01-02 15:04:05"),
+			changes,
+		)
+	}
+
+	w.Flush()
+	return nil
+}
+
+// shortID abbreviates a gist or revision ID for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
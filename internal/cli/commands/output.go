@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gist/internal/domain"
+)
+
+// outputUsage is the help text of the --output flag
+const outputUsage = "Output format: table or json (default: output setting)"
+
+// outputFormat returns the --output flag when given, else the output
+// setting
+func outputFormat(flag string, prefs domain.Preferences) (string, error) {
+	format := flag
+	if format == "" {
+		format = prefs.Output
+	}
+
+	switch format {
+	case "", domain.OutputTable:
+		return domain.OutputTable, nil
+	case domain.OutputJSON:
+		return domain.OutputJSON, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want table or json)", format)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// ProfileCommand handles the 'profile' command group to manage named
+// profiles
+type ProfileCommand struct {
+	service ProfileService
+}
+
+// NewProfileCommand creates a new profile command with its subcommands
+func NewProfileCommand(service ProfileService) *cobra.Command {
+	pc := &ProfileCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named profiles",
+		Long: `Manage the named profiles of ~/.gistconfig, each with its own GitHub
+credentials and cache.
+
+Create a profile with 'gist init --profile <name>'. Commands use the current
+profile unless --profile or GIST_PROFILE selects another one.`,
+	}
+
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE:  pc.RunList,
+	}
+
+	useCmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a profile the current one",
+		Args:  cobra.ExactArgs(1),
+		RunE:  pc.RunUse,
+	}
+
+	rmCmd := &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove a profile and its cache",
+		Args:  cobra.ExactArgs(1),
+		RunE:  pc.RunRemove,
+	}
+
+	cmd.AddCommand(lsCmd, useCmd, rmCmd)
+
+	return cmd
+}
+
+// RunList executes the profile ls command
+func (c *ProfileCommand) RunList(cmd *cobra.Command, args []string) error {
+	profiles, err := c.service.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println("No profiles configured")
+		fmt.Println("Create one with 'gist init --profile <name>'")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tGITHUB USER\tTOKEN STORE")
+	for _, profile := range profiles {
+		marker := ""
+		if profile.Current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, profile.Name, profile.GitHubUser, profile.TokenStore)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// RunUse executes the profile use command
+func (c *ProfileCommand) RunUse(cmd *cobra.Command, args []string) error {
+	if err := c.service.UseProfile(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Switched to profile %s\n", args[0])
+	return nil
+}
+
+// RunRemove executes the profile rm command
+func (c *ProfileCommand) RunRemove(cmd *cobra.Command, args []string) error {
+	if err := c.service.RemoveProfile(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Removed profile %s\n", args[0])
+	return nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"gist/internal/domain"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +12,7 @@ type PublishCommand struct {
 	service     GistService
 	description string
 	public      bool
+	private     bool
 	files       []string
 }
 
@@ -23,13 +25,18 @@ func NewPublishCommand(service GistService) *cobra.Command {
 		Aliases: []string{"new"},
 		Short:   "Create a gist from files",
 		Long: `Create a new GitHub gist from one or more files.
-Gists can be public or private and support descriptions with tags (e.g., "#golang #tutorial").`,
+Gists can be public or private and support descriptions with tags (e.g., "#golang #tutorial").
+
+New gists are private unless the defaults.visibility setting says public;
+the hashtags of defaults.tags are added to the description.`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: pc.Run,
 	}
 
 	cmd.Flags().StringVarP(&pc.description, "desc", "d", "", "Set description for the gist")
-	cmd.Flags().BoolVarP(&pc.public, "public", "p", false, "Make the gist public (default: private)")
+	cmd.Flags().BoolVarP(&pc.public, "public", "p", false, "Make the gist public (default: defaults.visibility setting)")
+	cmd.Flags().BoolVar(&pc.private, "private", false, "Make the gist private")
+	cmd.MarkFlagsMutuallyExclusive("public", "private")
 
 	return cmd
 }
@@ -41,7 +48,8 @@ func (c *PublishCommand) Run(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Publishing %d file(s) to GitHub...\n", len(c.files))
 
-	gistID, err := c.service.PublishFiles(ctx, c.files, c.description, c.public)
+	public := newGistPublic(c.public, c.private, c.service.Preferences())
+	gistID, err := c.service.PublishFiles(ctx, c.files, c.description, public)
 	if err != nil {
 		return fmt.Errorf("publish failed: %w", err)
 	}
@@ -49,3 +57,16 @@ func (c *PublishCommand) Run(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ Created gist: %s\n", gistID)
 	return nil
 }
+
+// newGistPublic decides the visibility of a new gist: --public or
+// --private when given, else the defaults.visibility setting
+func newGistPublic(public, private bool, prefs domain.Preferences) bool {
+	switch {
+	case public:
+		return true
+	case private:
+		return false
+	default:
+		return prefs.Public
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// PullCommand handles the 'pull' command to refresh a working copy
+type PullCommand struct {
+	service GistService
+	force   bool
+}
+
+// NewPullCommand creates a new pull command
+func NewPullCommand(service GistService) *cobra.Command {
+	pc := &PullCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "pull [dir]",
+		Short: "Fetch the latest version of a cloned gist",
+		Long: `Update a working copy created by 'gist clone' (the current directory by
+default) to the latest version of its gist.
+
+Files you edited locally are never overwritten; the pull is aborted and
+the files are listed instead. Use --force to discard local edits.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: pc.Run,
+	}
+
+	cmd.Flags().BoolVarP(&pc.force, "force", "f", false, "Overwrite locally modified files")
+
+	return cmd
+}
+
+// Run executes the pull command
+func (c *PullCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	changes, err := c.service.PullGist(ctx, dir, c.force)
+	if err != nil {
+		var local domain.ErrLocalChanges
+		if errors.As(err, &local) {
+			return fmt.Errorf("pull aborted: %w; rerun with --force to discard them", err)
+		}
+		return fmt.Errorf("pull failed: %w", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Already up to date")
+		return nil
+	}
+
+	printFileChanges(changes)
+	fmt.Printf("✓ Pulled %d file(s)\n", len(changes))
+	return nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// PushCommand handles the 'push' command to upload a working copy
+type PushCommand struct {
+	service GistService
+}
+
+// NewPushCommand creates a new push command
+func NewPushCommand(service GistService) *cobra.Command {
+	pc := &PushCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "push [dir]",
+		Short: "Upload local changes to a cloned gist",
+		Long: `Upload edits made in a working copy created by 'gist clone' (the current
+directory by default). Edited and new files are uploaded and files you
+deleted are removed from the gist.
+
+The push is refused if the gist changed on GitHub since the last clone or
+pull, so a teammate's edit is never silently overwritten. Run 'gist pull'
+first in that case.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: pc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the push command
+func (c *PushCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	changes, err := c.service.PushGist(ctx, dir)
+	if err != nil {
+		var conflict domain.ErrRemoteConflict
+		if errors.As(err, &conflict) {
+			return fmt.Errorf("push rejected: %w; run 'gist pull' first", err)
+		}
+		return fmt.Errorf("push failed: %w", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Nothing to push")
+		return nil
+	}
+
+	printFileChanges(changes)
+	fmt.Printf("✓ Pushed %d file(s)\n", len(changes))
+	return nil
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ResetCommand handles the 'reset' command to unstage files
+type ResetCommand struct {
+	service GistService
+}
+
+// NewResetCommand creates a new reset command
+func NewResetCommand(service GistService) *cobra.Command {
+	rc := &ResetCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "reset [files...]",
+		Short: "Unstage files",
+		Long: `Remove files from the staging area. With no arguments, everything is
+unstaged. Working copies are never touched.`,
+		RunE: rc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the reset command
+func (c *ResetCommand) Run(cmd *cobra.Command, args []string) error {
+	removed, err := c.service.UnstageFiles(args)
+	if err != nil {
+		return fmt.Errorf("reset failed: %w", err)
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("Nothing to unstage")
+		return nil
+	}
+
+	for _, file := range removed {
+		fmt.Printf("  unstaged %s\n", file.Filename)
+	}
+	return nil
+}
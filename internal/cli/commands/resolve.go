@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gist/internal/domain"
+)
+
+// resolveGist finds a gist by full ID or unique ID prefix. Cached gists are
+// searched first; if nothing matches, the ID is fetched directly from GitHub.
+func resolveGist(ctx context.Context, service GistService, gistID string) (*domain.Gist, error) {
+	if gistID == "" {
+		return nil, fmt.Errorf("gist ID must not be empty")
+	}
+
+	// First try to get from cache
+	gists, err := service.ListGists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get gists: %w", err)
+	}
+
+	// Find matching gist (supporting partial ID match)
+	var gist *domain.Gist
+	var matchedIDs []string
+	for i := range gists {
+		if strings.HasPrefix(string(gists[i].ID), gistID) {
+			if gist == nil {
+				gist = &gists[i]
+			}
+			matchedIDs = append(matchedIDs, string(gists[i].ID))
+		}
+	}
+
+	if len(matchedIDs) > 1 {
+		return nil, fmt.Errorf("ambiguous gist ID %q matches %d gists: %s", gistID, len(matchedIDs), strings.Join(matchedIDs, ", "))
+	}
+
+	if gist == nil {
+		// Try fetching directly from GitHub
+		fullGist, err := service.GetGist(ctx, gistID)
+		if err != nil {
+			return nil, fmt.Errorf("gist not found: %s", gistID)
+		}
+		gist = fullGist
+	}
+
+	return gist, nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// RestoreCommand handles the 'restore' command to roll a gist back
+type RestoreCommand struct {
+	service  GistService
+	revision string
+	dryRun   bool
+}
+
+// NewRestoreCommand creates a new restore command
+func NewRestoreCommand(service GistService) *cobra.Command {
+	rc := &RestoreCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "restore <gist-id> --rev <revision>",
+		Short: "Roll a gist back to an earlier revision",
+		Long: `Make the files of a gist match an earlier revision.
+
+The old file contents are pushed as a new revision, so the restore itself
+can be undone. Files that did not exist at that revision are deleted. The
+description is not changed. Revisions are listed by 'gist log' and may be
+abbreviated to a unique prefix.`,
+		Args: cobra.ExactArgs(1),
+		RunE: rc.Run,
+	}
+
+	cmd.Flags().StringVarP(&rc.revision, "rev", "r", "", "Revision to restore (required)")
+	cmd.Flags().BoolVarP(&rc.dryRun, "dry-run", "n", false, "Show what would change without updating")
+	_ = cmd.MarkFlagRequired("rev")
+
+	return cmd
+}
+
+// Run executes the restore command
+func (c *RestoreCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	gist, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	update, changes, err := c.service.PrepareRestore(ctx, string(gist.ID), c.revision)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	fmt.Printf("Restoring gist %s to revision %s:\n", gist.ID, c.revision)
+	printFileChanges(changes)
+
+	if len(update.Files) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	if c.dryRun {
+		return nil
+	}
+
+	if err := c.service.UpdateGist(ctx, update); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	fmt.Printf("✓ Restored gist: %s\n", update.ID)
+	return nil
+}
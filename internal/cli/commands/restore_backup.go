@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// RestoreBackupCommand handles the 'restore-backup' command
+type RestoreBackupCommand struct {
+	service BackupService
+}
+
+// NewRestoreBackupCommand creates a new restore-backup command
+func NewRestoreBackupCommand(service BackupService) *cobra.Command {
+	rc := &RestoreBackupCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "restore-backup <archive.tar.gz|dir>",
+		Short: "Recreate gists from a backup",
+		Long: `Recreate the gists of a backup made with 'gist backup' on the configured
+account, which may differ from the one that was backed up.
+
+Gists that already exist (same ID, or same description and filenames) are
+skipped, so a restore can safely be repeated. Recreated gists get new IDs;
+the report maps each old ID to its new one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: rc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the restore-backup command
+func (c *RestoreBackupCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	results, err := c.service.RestoreBackup(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OLD ID\tNEW ID\tSTATUS")
+
+	var created, failed int
+	for _, result := range results {
+		status := string(result.Status)
+		switch result.Status {
+		case domain.RestoreCreated:
+			created++
+		case domain.RestoreFailed:
+			failed++
+			status = fmt.Sprintf("failed: %v", result.Err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.OldID, result.NewID, status)
+	}
+	w.Flush()
+
+	fmt.Printf("\n✓ Restored %d of %d gist(s)\n", created, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d gist(s) could not be restored", failed)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"time"
 
 	"gist/internal/domain"
 )
@@ -11,5 +12,60 @@ type GistService interface {
 	ListGists(ctx context.Context) ([]domain.Gist, error)
 	GetGist(ctx context.Context, id string) (*domain.Gist, error)
 	PublishFiles(ctx context.Context, paths []string, description string, public bool) (string, error)
-	SyncGists(ctx context.Context) ([]domain.Gist, error)
+	SyncGists(ctx context.Context, full bool) (*domain.SyncResult, error)
+	PrepareUpdate(ctx context.Context, id string, paths []string, description string) (*domain.Gist, []domain.FileChange, error)
+	UpdateGist(ctx context.Context, gist *domain.Gist) error
+	DeleteGist(ctx context.Context, id string) error
+	GistHistory(ctx context.Context, id string) ([]domain.GistRevision, error)
+	GetGistRevision(ctx context.Context, id, revision string) (*domain.Gist, error)
+	DiffRevisions(ctx context.Context, id, from, to string) (*domain.Gist, *domain.Gist, error)
+	PrepareRestore(ctx context.Context, id, revision string) (*domain.Gist, []domain.FileChange, error)
+	CloneGist(ctx context.Context, id, dir string) (*domain.WorkingCopy, error)
+	PullGist(ctx context.Context, dir string, force bool) ([]domain.FileChange, error)
+	PushGist(ctx context.Context, dir string) ([]domain.FileChange, error)
+	StageFiles(paths []string) ([]domain.StagedFile, error)
+	UnstageFiles(paths []string) ([]domain.StagedFile, error)
+	StagingStatus() ([]domain.StagedFileStatus, error)
+	CommitStaged(ctx context.Context, description string, public bool, targetID string) (string, error)
+	Preferences() domain.Preferences
+}
+
+// BackupService defines the backup operations needed by CLI commands.
+type BackupService interface {
+	BackupGists(ctx context.Context, location string) (*domain.BackupManifest, error)
+	RestoreBackup(ctx context.Context, location string) ([]domain.RestoreResult, error)
+}
+
+// AuthService defines the credential checks needed by CLI commands.
+type AuthService interface {
+	AuthStatus(ctx context.Context) (*domain.AuthStatus, error)
+}
+
+// LoginService defines the sign-in operations needed by CLI commands.
+type LoginService interface {
+	DeviceLogin(ctx context.Context, config *domain.Config, prompt func(code *domain.DeviceCode)) (*domain.AuthStatus, error)
+}
+
+// CacheService defines the cache maintenance operations needed by CLI commands.
+type CacheService interface {
+	CacheStats() (*domain.CacheStats, error)
+	CacheEntries() ([]domain.CacheEntry, error)
+	PruneCache(olderThan time.Duration) (int, error)
+	ClearCache() error
+}
+
+// SettingsService defines the settings operations needed by CLI commands.
+type SettingsService interface {
+	Settings() ([]domain.SettingValue, error)
+	GetSetting(name string) (*domain.SettingValue, error)
+	SetSetting(name, value string, project bool) error
+	SettingsPath(project bool) (string, error)
+	CheckSettings(project bool) error
+}
+
+// ProfileService defines the profile operations needed by CLI commands.
+type ProfileService interface {
+	ListProfiles() ([]domain.Profile, error)
+	UseProfile(name string) error
+	RemoveProfile(name string) error
 }
@@ -12,6 +12,7 @@ import (
 // ShowCommand handles the 'show' command to display gist details
 type ShowCommand struct {
 	service GistService
+	output  string
 }
 
 // NewShowCommand creates a new show command
@@ -24,51 +25,38 @@ func NewShowCommand(service GistService) *cobra.Command {
 		Long: `Show detailed information about a specific gist.
 
 The gist ID can be the full ID or a prefix (e.g., "a1b2c3d4" or "a1b2").
-If not found in cache, will fetch directly from GitHub.`,
+If not found in cache, will fetch directly from GitHub. Gist contents are
+cached per gist and revalidated with GitHub once the cached copy expires.`,
 		Args: cobra.ExactArgs(1),
 		RunE: sc.Run,
 	}
 
+	cmd.Flags().StringVarP(&sc.output, "output", "o", "", outputUsage)
+
 	return cmd
 }
 
 // Run executes the show command
 func (c *ShowCommand) Run(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	gistID := args[0]
-	if gistID == "" {
-		return fmt.Errorf("gist ID must not be empty")
-	}
-
-	// First try to get from cache
-	gists, err := c.service.ListGists(ctx)
+	format, err := outputFormat(c.output, c.service.Preferences())
 	if err != nil {
-		return fmt.Errorf("get gists: %w", err)
+		return err
 	}
 
-	// Find matching gist (supporting partial ID match)
-	var gist *domain.Gist
-	var matchedIDs []string
-	for i := range gists {
-		if strings.HasPrefix(string(gists[i].ID), gistID) {
-			if gist == nil {
-				gist = &gists[i]
-			}
-			matchedIDs = append(matchedIDs, string(gists[i].ID))
-		}
+	match, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
 	}
 
-	if len(matchedIDs) > 1 {
-		return fmt.Errorf("ambiguous gist ID %q matches %d gists: %s", gistID, len(matchedIDs), strings.Join(matchedIDs, ", "))
+	// The cached listing may hold partial content; fetch the full gist
+	gist, err := c.service.GetGist(ctx, string(match.ID))
+	if err != nil {
+		return fmt.Errorf("get gist %s: %w", match.ID, err)
 	}
 
-	if gist == nil {
-		// Try fetching directly from GitHub
-		fullGist, err := c.service.GetGist(ctx, gistID)
-		if err != nil {
-			return fmt.Errorf("gist not found: %s", gistID)
-		}
-		gist = fullGist
+	if format == domain.OutputJSON {
+		return printJSON(gist)
 	}
 
 	// Display gist details
@@ -109,7 +97,11 @@ func (c *ShowCommand) displayGist(gist *domain.Gist) {
 		lines := strings.Count(file.Content, "\n") + 1
 		size := len(file.Content)
 
-		fmt.Printf("  - %s (%d lines, %d bytes)\n", filename, lines, size)
+		if file.Language != "" {
+			fmt.Printf("  - %s (%s, %d lines, %d bytes)\n", filename, file.Language, lines, size)
+		} else {
+			fmt.Printf("  - %s (%d lines, %d bytes)\n", filename, lines, size)
+		}
 	}
 
 	// Show content preview if only one file
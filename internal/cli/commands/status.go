@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// StatusCommand handles the 'status' command to show the staging area
+type StatusCommand struct {
+	service GistService
+}
+
+// NewStatusCommand creates a new status command
+func NewStatusCommand(service GistService) *cobra.Command {
+	sc := &StatusCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show staged files",
+		Long: `Show the files in the staging area and whether their working copies
+have changed since they were staged.`,
+		Args: cobra.NoArgs,
+		RunE: sc.Run,
+	}
+
+	return cmd
+}
+
+// Run executes the status command
+func (c *StatusCommand) Run(cmd *cobra.Command, args []string) error {
+	statuses, err := c.service.StagingStatus()
+	if err != nil {
+		return fmt.Errorf("status failed: %w", err)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("Nothing staged")
+		fmt.Println("Use 'gist add <file>' to stage files")
+		return nil
+	}
+
+	fmt.Println("Staged files:")
+	var stale bool
+	for _, status := range statuses {
+		switch status.State {
+		case domain.StageModified:
+			fmt.Printf("  modified:  %s (%s)\n", status.Filename, status.Path)
+			stale = true
+		case domain.StageMissing:
+			fmt.Printf("  missing:   %s (%s)\n", status.Filename, status.Path)
+			stale = true
+		default:
+			fmt.Printf("  staged:    %s (%s)\n", status.Filename, status.Path)
+		}
+	}
+
+	if stale {
+		fmt.Println("\nModified files are committed as staged; run 'gist add' again to update them")
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 // SyncCommand handles the 'sync' command to sync gists from GitHub
 type SyncCommand struct {
 	service GistService
+	full    bool
 }
 
 // NewSyncCommand creates a new sync command
@@ -18,13 +19,18 @@ func NewSyncCommand(service GistService) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Sync gists from GitHub",
-		Long: `Force a sync of gists from GitHub to local cache.
+		Long: `Sync gists from GitHub to local cache.
 
-This command will refresh your local gist cache by fetching the latest
-gists from your GitHub account, overwriting any locally cached versions.`,
+By default only gists updated since the last sync are fetched and merged
+into the cache. A full sync, which also drops gists deleted on GitHub, runs
+automatically once a day (GIST_FULL_SYNC_INTERVAL, in seconds).
+
+Use --full to clear the local cache and refetch every gist.`,
 		RunE: sc.Run,
 	}
 
+	cmd.Flags().BoolVar(&sc.full, "full", false, "Clear the cache and refetch every gist")
+
 	return cmd
 }
 
@@ -34,12 +40,15 @@ func (c *SyncCommand) Run(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("Syncing gists from GitHub...")
 
-	// Force refresh from GitHub
-	gists, err := c.service.SyncGists(ctx)
+	result, err := c.service.SyncGists(ctx, c.full)
 	if err != nil {
 		return fmt.Errorf("sync gists: %w", err)
 	}
 
-	fmt.Printf("✓ Synced %d gist(s)\n", len(gists))
+	if result.Full {
+		fmt.Printf("✓ Synced %d gist(s)\n", len(result.Gists))
+	} else {
+		fmt.Printf("✓ Synced %d gist(s), %d updated since last sync\n", len(result.Gists), result.Updated)
+	}
 	return nil
 }
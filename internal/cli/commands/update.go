@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+
+	"gist/internal/domain"
+	"github.com/spf13/cobra"
+)
+
+// UpdateCommand handles the 'update' command to republish files into a gist
+type UpdateCommand struct {
+	service     GistService
+	description string
+	dryRun      bool
+}
+
+// NewUpdateCommand creates a new update command
+func NewUpdateCommand(service GistService) *cobra.Command {
+	uc := &UpdateCommand{service: service}
+
+	cmd := &cobra.Command{
+		Use:   "update <gist-id> <files...>",
+		Short: "Update an existing gist from files",
+		Long: `Replace files in an existing gist with local files, keeping its ID and URL.
+
+Files are matched by name; files not yet in the gist are added. Use --desc
+to replace the description; hashtags from the old description are kept
+unless the new one already has them. A per-file summary is printed before
+anything is pushed.
+
+The gist ID can be the full ID or a prefix (e.g., "a1b2c3d4" or "a1b2").`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: uc.Run,
+	}
+
+	cmd.Flags().StringVarP(&uc.description, "desc", "d", "", "Replace the gist description (hashtags are preserved)")
+	cmd.Flags().BoolVarP(&uc.dryRun, "dry-run", "n", false, "Show what would change without updating")
+
+	return cmd
+}
+
+// Run executes the update command
+func (c *UpdateCommand) Run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	gist, err := resolveGist(ctx, c.service, args[0])
+	if err != nil {
+		return err
+	}
+
+	update, changes, err := c.service.PrepareUpdate(ctx, string(gist.ID), args[1:], c.description)
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	descChanged := update.Description != gist.Description
+	fmt.Printf("Gist: %s\n", gist.ID)
+	if descChanged {
+		fmt.Printf("  description: %q -> %q\n", gist.Description, update.Description)
+	}
+	printFileChanges(changes)
+
+	if len(update.Files) == 0 && !descChanged {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	if c.dryRun {
+		return nil
+	}
+
+	if err := c.service.UpdateGist(ctx, update); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	fmt.Printf("✓ Updated gist: %s\n", update.ID)
+	return nil
+}
+
+// printFileChanges prints one summary line per file
+func printFileChanges(changes []domain.FileChange) {
+	for _, change := range changes {
+		switch change.Status {
+		case domain.FileAdded:
+			fmt.Printf("  A %s (+%d)\n", change.Filename, change.LinesAdded)
+		case domain.FileModified:
+			fmt.Printf("  M %s (+%d -%d)\n", change.Filename, change.LinesAdded, change.LinesRemoved)
+		case domain.FileDeleted:
+			fmt.Printf("  D %s (-%d)\n", change.Filename, change.LinesRemoved)
+		default:
+			fmt.Printf("    %s (unchanged)\n", change.Filename)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package diff computes line-based differences between two texts.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op identifies what happened to a line
+type Op int
+
+const (
+	// Equal marks a line present in both texts
+	Equal Op = iota
+	// Insert marks a line only present in the new text
+	Insert
+	// Delete marks a line only present in the old text
+	Delete
+)
+
+// Line is a single line of a diff. Text has no line terminator;
+// NoNewline marks a last line that had none either.
+type Line struct {
+	Op        Op
+	Text      string
+	NoNewline bool
+}
+
+// maxMatrixCells bounds the LCS table. Larger differing regions are reported
+// as a full replacement instead of an exact diff.
+const maxMatrixCells = 4_000_000
+
+// Lines returns the line diff turning a into b. The common prefix and suffix
+// are matched directly; the differing middle is aligned by longest common
+// subsequence.
+func Lines(a, b string) []Line {
+	lines := diffLines(splitLines(a), splitLines(b))
+	for i, line := range lines {
+		text, terminated := strings.CutSuffix(line.Text, "\n")
+		lines[i].Text, lines[i].NoNewline = text, !terminated
+	}
+	return lines
+}
+
+// diffLines diffs lines that still carry their terminators
+func diffLines(as, bs []string) []Line {
+	prefix := 0
+	for prefix < len(as) && prefix < len(bs) && as[prefix] == bs[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(as)-prefix && suffix < len(bs)-prefix &&
+		as[len(as)-1-suffix] == bs[len(bs)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for _, text := range as[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	lines = append(lines, middle(as[prefix:len(as)-suffix], bs[prefix:len(bs)-suffix])...)
+	for _, text := range as[len(as)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+
+	return lines
+}
+
+// Stat counts inserted and deleted lines
+func Stat(lines []Line) (added, removed int) {
+	for _, line := range lines {
+		switch line.Op {
+		case Insert:
+			added++
+		case Delete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// middle diffs the region between the common prefix and suffix
+func middle(a, b []string) []Line {
+	var lines []Line
+	if len(a)*len(b) > maxMatrixCells {
+		for _, text := range a {
+			lines = append(lines, Line{Op: Delete, Text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Op: Insert, Text: text})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Op: Delete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Op: Insert, Text: b[j]})
+	}
+
+	return lines
+}
+
+// splitLines splits text into lines that keep their terminators, so a
+// last line without a newline differs from the same line with one. A
+// trailing newline does not produce an extra empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Unified renders lines as a unified diff with the given number of context
+// lines around each change. It returns "" when the texts are identical.
+func Unified(oldName, newName string, lines []Line, context int) string {
+	var changed []int
+	for i, line := range lines {
+		if line.Op != Equal {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldAt/newAt hold the 1-based line numbers reached before index i
+	oldAt := make([]int, len(lines)+1)
+	newAt := make([]int, len(lines)+1)
+	oldAt[0], newAt[0] = 1, 1
+	for i, line := range lines {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if line.Op != Insert {
+			oldAt[i+1]++
+		}
+		if line.Op != Delete {
+			newAt[i+1]++
+		}
+	}
+
+	for k := 0; k < len(changed); {
+		start := max(changed[k]-context, 0)
+		end := changed[k]
+		// Merge changes whose context windows touch into a single hunk
+		for k < len(changed) && changed[k]-end-1 <= 2*context {
+			end = changed[k]
+			k++
+		}
+		end = min(end+context+1, len(lines))
+
+		oldCount := oldAt[end] - oldAt[start]
+		newCount := newAt[end] - newAt[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldAt[start], oldCount), hunkRange(newAt[start], newCount))
+		for _, line := range lines[start:end] {
+			switch line.Op {
+			case Insert:
+				b.WriteString("+")
+			case Delete:
+				b.WriteString("-")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line.Text)
+			b.WriteString("\n")
+			if line.NoNewline {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// hunkRange formats a hunk header range. An empty range refers to the line
+// before it, as in GNU diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
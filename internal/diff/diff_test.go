@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines_Stat(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           string
+		added, removed int
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 0, 0},
+		{"empty to text", "", "a\nb\n", 2, 0},
+		{"text to empty", "a\nb\n", "", 0, 2},
+		{"modify middle", "a\nb\nc\n", "a\nx\nc\n", 1, 1},
+		{"insert", "a\nc\n", "a\nb\nc\n", 1, 0},
+		{"delete", "a\nb\nc\n", "a\nc\n", 0, 1},
+		{"final newline added", "a", "a\n", 1, 1},
+		{"no final newline in both", "a\nb", "a\nb", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := Stat(Lines(tt.a, tt.b))
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("Stat = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+}
+
+func TestLines_Order(t *testing.T) {
+	got := Lines("a\nb\nc\n", "a\nx\nc\n")
+	want := []Line{{Op: Equal, Text: "a"}, {Op: Delete, Text: "b"}, {Op: Insert, Text: "x"}, {Op: Equal, Text: "c"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\nten\n"
+
+	got := Unified("a/f", "b/f", Lines(a, b), 1)
+	want := `--- a/f
++++ b/f
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+@@ -9 +9,2 @@
+ 9
++ten
+`
+	if got != want {
+		t.Errorf("Unified mismatch:\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_MergesNearbyHunks(t *testing.T) {
+	got := Unified("a", "b", Lines("1\n2\n3\n4\n", "x\n2\n3\ny\n"), 1)
+	if n := strings.Count(got, "@@ -"); n != 1 {
+		t.Errorf("expected changes within 2*context merged into one hunk, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,4 +1,4 @@") {
+		t.Errorf("unexpected header:\n%s", got)
+	}
+}
+
+func TestUnified_NewFile(t *testing.T) {
+	got := Unified("/dev/null", "b/f", Lines("", "a\nb\n"), 3)
+	if !strings.Contains(got, "@@ -0,0 +1,2 @@") {
+		t.Errorf("unexpected header for new file:\n%s", got)
+	}
+}
+
+func TestUnified_FinalNewline(t *testing.T) {
+	got := Unified("a/f", "b/f", Lines("1\n2\n", "1\n2"), 3)
+	want := `--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+ 1
+-2
++2
+\ No newline at end of file
+`
+	if got != want {
+		t.Errorf("removing the final newline:\n got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if added, removed := Stat(Lines("1\n2", "1\n2\n")); added != 1 || removed != 1 {
+		t.Errorf("expected adding the final newline counted as a changed line, got +%d -%d", added, removed)
+	}
+	if got := Unified("a", "b", Lines("x", "x"), 3); got != "" {
+		t.Errorf("expected no diff between texts both lacking a final newline, got %q", got)
+	}
+}
+
+func TestUnified_Identical(t *testing.T) {
+	if got := Unified("a", "b", Lines("x\n", "x\n"), 3); got != "" {
+		t.Errorf("expected empty diff, got %q", got)
+	}
+}
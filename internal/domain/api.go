@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultAPIURL is the API of github.com. GitHub Enterprise Server serves
+// its API under /api/v3 on the instance host.
+const DefaultAPIURL = "https://api.github.com"
+
+// enterpriseAPIPath is the API path of GitHub Enterprise Server
+const enterpriseAPIPath = "/api/v3"
+
+// NormalizeAPIURL checks a configured API URL and returns it without a
+// trailing slash. Empty means github.com and is returned as is. A bare
+// GitHub Enterprise Server host such as https://github.example.com gets
+// the /api/v3 path appended.
+func NormalizeAPIURL(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", ErrInvalidAPIURL{URL: raw, Reason: err.Error()}
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", ErrInvalidAPIURL{URL: raw, Reason: "use an http or https URL"}
+	}
+	if u.Host == "" {
+		return "", ErrInvalidAPIURL{URL: raw, Reason: "no host"}
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", ErrInvalidAPIURL{URL: raw, Reason: "no query or fragment allowed"}
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if u.Path == "" && u.Host != "api.github.com" {
+		u.Path = enterpriseAPIPath
+	}
+	normalized := u.String()
+	if normalized == DefaultAPIURL {
+		return "", nil
+	}
+	return normalized, nil
+}
+
+// APIURLOrDefault returns apiURL, or DefaultAPIURL when it is empty
+func APIURLOrDefault(apiURL string) string {
+	if apiURL == "" {
+		return DefaultAPIURL
+	}
+	return apiURL
+}
+
+// WebURL returns the web address of the GitHub instance serving apiURL,
+// which also hosts its OAuth endpoints: https://github.com for github.com,
+// the instance root for GitHub Enterprise Server
+func WebURL(apiURL string) string {
+	if apiURL == "" {
+		return "https://github.com"
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "https://github.com"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// WebHost returns the host of WebURL, naming the instance a token is for
+func WebHost(apiURL string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(WebURL(apiURL), "https://"), "http://")
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// GistScope is the OAuth scope a token needs to manage gists
+const GistScope = "gist"
+
+// AuthStatus describes the account and token a GitHub API request was
+// authenticated with
+type AuthStatus struct {
+	APIURL string
+	// Enterprise is the GitHub Enterprise Server version, empty for
+	// github.com
+	Enterprise string
+	Login      string
+	// Scopes are the token's OAuth scopes. ScopesKnown is false when GitHub
+	// reports none at all, as for fine-grained tokens, whose permissions
+	// cannot be read back.
+	Scopes             []string
+	ScopesKnown        bool
+	RateLimitRemaining int
+	RateLimitLimit     int
+	RateLimitReset     time.Time
+}
+
+// HasScope reports whether the token was granted scope
+func (s *AuthStatus) HasScope(scope string) bool {
+	for _, granted := range s.Scopes {
+		if strings.EqualFold(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeviceCode is an OAuth device authorization in progress: the user enters
+// UserCode at VerificationURI while the CLI polls for the token every
+// Interval until ExpiresAt. A zero ExpiresAt means no known deadline.
+type DeviceCode struct {
+	DeviceCode      string
+	UserCode        string
+	VerificationURI string
+	ExpiresAt       time.Time
+	Interval        time.Duration
+}
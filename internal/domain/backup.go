@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// BackupManifestFile is the manifest path inside a backup
+const BackupManifestFile = "manifest.json"
+
+// BackupManifest describes every gist stored in a backup
+type BackupManifest struct {
+	CreatedAt time.Time     `json:"created_at"`
+	User      string        `json:"user"`
+	Gists     []BackupEntry `json:"gists"`
+}
+
+// BackupEntry records the metadata of one backed-up gist. File contents are
+// stored next to the manifest under gists/<id>/<filename>.
+type BackupEntry struct {
+	ID          GistID    `json:"id"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	HTMLURL     string    `json:"html_url"`
+	Files       []string  `json:"files"`
+}
+
+// BackupFilePath returns where a gist file is stored inside a backup
+func BackupFilePath(id GistID, filename string) string {
+	return "gists/" + string(id) + "/" + filename
+}
+
+// RestoreStatus describes what happened to a gist during a restore
+type RestoreStatus string
+
+const (
+	// RestoreCreated means the gist was recreated under a new ID
+	RestoreCreated RestoreStatus = "created"
+	// RestoreExists means the gist, or an identical one, already exists
+	RestoreExists RestoreStatus = "exists"
+	// RestoreFailed means the gist could not be recreated
+	RestoreFailed RestoreStatus = "failed"
+)
+
+// RestoreResult maps a backed-up gist to the gist it corresponds to after a
+// restore
+type RestoreResult struct {
+	OldID  GistID
+	NewID  GistID
+	Status RestoreStatus
+	Err    error
+}
@@ -0,0 +1,23 @@
+package domain
+
+// FileStatus describes how a local file relates to its copy in a gist
+type FileStatus string
+
+const (
+	// FileAdded is a file not yet present in the gist
+	FileAdded FileStatus = "added"
+	// FileModified is a file whose content differs from the gist
+	FileModified FileStatus = "modified"
+	// FileDeleted is a gist file that will be removed
+	FileDeleted FileStatus = "deleted"
+	// FileUnchanged is a file identical to the gist copy
+	FileUnchanged FileStatus = "unchanged"
+)
+
+// FileChange summarizes the difference for a single file
+type FileChange struct {
+	Filename     string
+	Status       FileStatus
+	LinesAdded   int
+	LinesRemoved int
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -49,6 +50,9 @@ type ErrAPIRequest struct {
 }
 
 func (e ErrAPIRequest) Error() string {
+	if e.StatusCode == 401 {
+		return fmt.Sprintf("GitHub API error %d: %s; check the token with 'gist auth status'", e.StatusCode, e.Message)
+	}
 	return fmt.Sprintf("GitHub API error %d: %s", e.StatusCode, e.Message)
 }
 
@@ -76,3 +80,225 @@ type ErrRateLimitExceeded struct {
 func (e ErrRateLimitExceeded) Error() string {
 	return fmt.Sprintf("rate limit exceeded, resets at %s", e.Reset.Format(time.RFC3339))
 }
+
+// ErrRevisionNotFound represents an unknown gist revision
+type ErrRevisionNotFound struct {
+	ID       GistID
+	Revision string
+}
+
+func (e ErrRevisionNotFound) Error() string {
+	return fmt.Sprintf("revision %s not found in gist %s", e.Revision, e.ID)
+}
+
+// ErrNotWorkingCopy represents a directory that is not a gist working copy
+type ErrNotWorkingCopy struct {
+	Dir string
+}
+
+func (e ErrNotWorkingCopy) Error() string {
+	return fmt.Sprintf("not a gist working copy: %s (no %s)", e.Dir, WorkingCopyFile)
+}
+
+// ErrLocalChanges represents local edits that an operation would overwrite
+type ErrLocalChanges struct {
+	Files []string
+}
+
+func (e ErrLocalChanges) Error() string {
+	return fmt.Sprintf("local changes would be overwritten: %s", strings.Join(e.Files, ", "))
+}
+
+// ErrEmptyFile represents an update that empties a gist file. GitHub does
+// not keep empty files and ignores the change, so it must be a delete.
+type ErrEmptyFile struct {
+	Filename string
+}
+
+func (e ErrEmptyFile) Error() string {
+	return fmt.Sprintf("%s is empty; GitHub does not keep empty gist files, delete it instead", e.Filename)
+}
+
+// ErrRemoteConflict represents a gist that changed remotely since a working
+// copy last synced with it
+type ErrRemoteConflict struct {
+	ID    GistID
+	Files []string
+}
+
+func (e ErrRemoteConflict) Error() string {
+	if len(e.Files) == 0 {
+		return fmt.Sprintf("gist %s changed remotely since last pull", e.ID)
+	}
+	return fmt.Sprintf("gist %s changed remotely since last pull: %s", e.ID, strings.Join(e.Files, ", "))
+}
+
+// ErrPartialFetch represents a paginated fetch that failed part-way through
+type ErrPartialFetch struct {
+	Page    int
+	Fetched int
+	Err     error
+}
+
+func (e ErrPartialFetch) Error() string {
+	return fmt.Sprintf("fetch page %d failed after %d items: %v", e.Page, e.Fetched, e.Err)
+}
+
+func (e ErrPartialFetch) Unwrap() error {
+	return e.Err
+}
+
+// ErrNotModified reports that a conditional request matched the cached
+// validators, so the cached data is still current
+type ErrNotModified struct{}
+
+func (e ErrNotModified) Error() string {
+	return "not modified since last fetch"
+}
+
+// ErrNetwork represents a failure to reach the GitHub API
+type ErrNetwork struct {
+	Err error
+}
+
+func (e ErrNetwork) Error() string {
+	return fmt.Sprintf("GitHub is unreachable: %v", e.Err)
+}
+
+func (e ErrNetwork) Unwrap() error {
+	return e.Err
+}
+
+// ErrOffline represents a request refused because offline mode is on
+type ErrOffline struct{}
+
+func (e ErrOffline) Error() string {
+	return "offline mode is on"
+}
+
+// ErrCacheLocked represents a cache that another process kept locked for
+// longer than the lock timeout
+type ErrCacheLocked struct {
+	Path string
+}
+
+func (e ErrCacheLocked) Error() string {
+	return fmt.Sprintf("cache %s is locked by another gist process", e.Path)
+}
+
+// ErrProfileNotFound represents a profile missing from the config file
+type ErrProfileNotFound struct {
+	Name string
+}
+
+func (e ErrProfileNotFound) Error() string {
+	return fmt.Sprintf("profile %q not found; run 'gist init --profile %s' to create it", e.Name, e.Name)
+}
+
+// ErrInvalidProfile represents a profile name that cannot be used
+type ErrInvalidProfile struct {
+	Name string
+}
+
+func (e ErrInvalidProfile) Error() string {
+	return fmt.Sprintf("invalid profile name %q: use letters, digits, '-' and '_'", e.Name)
+}
+
+// ErrSecretNotFound represents a token missing from a secret store
+type ErrSecretNotFound struct {
+	Key string
+}
+
+func (e ErrSecretNotFound) Error() string {
+	return fmt.Sprintf("no token stored for %s", e.Key)
+}
+
+// ErrUnknownTokenStore represents a token store name that is not supported
+type ErrUnknownTokenStore struct {
+	Store string
+}
+
+func (e ErrUnknownTokenStore) Error() string {
+	return fmt.Sprintf("unknown token store %q (want plain, file or helper)", e.Store)
+}
+
+// ErrInvalidAPIURL represents an API URL that is malformed or does not
+// serve the GitHub API
+type ErrInvalidAPIURL struct {
+	URL    string
+	Reason string
+}
+
+func (e ErrInvalidAPIURL) Error() string {
+	return fmt.Sprintf("invalid GitHub API URL %q: %s", e.URL, e.Reason)
+}
+
+// ErrBadCredentials represents a token GitHub does not accept
+type ErrBadCredentials struct{}
+
+func (e ErrBadCredentials) Error() string {
+	return "GitHub rejected the token; it may be mistyped, expired or revoked"
+}
+
+// ErrLoginMismatch represents a token that belongs to another account than
+// the configured user
+type ErrLoginMismatch struct {
+	Configured string
+	Login      string
+}
+
+func (e ErrLoginMismatch) Error() string {
+	return fmt.Sprintf("token belongs to %s, not the configured user %s", e.Login, e.Configured)
+}
+
+// ErrMissingScope represents a token lacking an OAuth scope gist needs
+type ErrMissingScope struct {
+	Scope string
+}
+
+func (e ErrMissingScope) Error() string {
+	return fmt.Sprintf("token lacks the %q scope", e.Scope)
+}
+
+// ErrDeviceFlow represents an OAuth device flow that GitHub ended without a
+// token, such as a denied or expired authorization
+type ErrDeviceFlow struct {
+	Code        string
+	Description string
+}
+
+func (e ErrDeviceFlow) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("device login failed: %s (%s)", e.Description, e.Code)
+	}
+	return fmt.Sprintf("device login failed: %s", e.Code)
+}
+
+// ErrUnknownSetting represents a settings key gist does not know
+type ErrUnknownSetting struct {
+	Key string
+}
+
+func (e ErrUnknownSetting) Error() string {
+	return fmt.Sprintf("unknown setting %q; run 'gist config list' to see the keys", e.Key)
+}
+
+// ErrInvalidSetting represents a settings value that cannot be used
+type ErrInvalidSetting struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+func (e ErrInvalidSetting) Error() string {
+	return fmt.Sprintf("invalid value %q for %s: %s", e.Value, e.Key, e.Reason)
+}
+
+// ErrSettingNotAllowed represents a key a project settings file may not set
+type ErrSettingNotAllowed struct {
+	Key string
+}
+
+func (e ErrSettingNotAllowed) Error() string {
+	return fmt.Sprintf("%s cannot be set in a project's .gist.yaml; set it in your user settings", e.Key)
+}
@@ -19,17 +19,69 @@ func (id GistID) String() string {
 	return string(id)
 }
 
-// CacheConfig holds cache configuration
+// Cache backends selectable with CacheConfig.Backend
+const (
+	CacheBackendFile = "file"
+	CacheBackendBolt = "bolt"
+)
+
+// DefaultDetailRetention is how long a cached gist detail is kept after it
+// was fetched when CacheConfig.DetailRetention is unset
+const DefaultDetailRetention = 30 * 24 * time.Hour
+
+// CacheConfig holds cache configuration. An empty Dir selects the default
+// cache directory, ~/.gist-cache. A gist detail past DetailTTL is stale and
+// revalidated before use, but kept for revalidation and offline reads until
+// DetailRetention has passed.
 type CacheConfig struct {
-	TTL         time.Duration
-	CleanupFreq time.Duration
+	Dir              string
+	Backend          string
+	TTL              time.Duration
+	DetailTTL        time.Duration
+	DetailRetention  time.Duration
+	CleanupFreq      time.Duration
+	FullSyncInterval time.Duration
 }
 
-// Config holds GitHub authentication configuration
+// Token stores selectable with Config.TokenStore. The plain store keeps the
+// token in the config file itself.
+const (
+	TokenStorePlain  = "plain"
+	TokenStoreFile   = "file"
+	TokenStoreHelper = "helper"
+)
+
+// CheckTokenStore reports whether store names a token store, with the
+// helper command the helper store needs
+func CheckTokenStore(store, helper string) error {
+	switch store {
+	case "", TokenStorePlain, TokenStoreFile:
+		return nil
+	case TokenStoreHelper:
+		if helper == "" {
+			return ErrConfigMissing{Field: "credential helper command"}
+		}
+		return nil
+	default:
+		return ErrUnknownTokenStore{Store: store}
+	}
+}
+
+// Config holds GitHub authentication configuration. Profile names the
+// config file profile it was loaded from; it is empty for credentials from
+// the environment. TokenStore selects where the config file keeps the token,
+// and CredentialHelper is the command of the helper store. APIURL is the
+// GitHub API to use; empty means github.com.
 type Config struct {
-	GitHubUser  string
-	GitHubToken string
-	Cache       CacheConfig
+	Profile          string
+	GitHubUser       string
+	GitHubToken      string
+	TokenStore       string
+	CredentialHelper string
+	APIURL           string
+	PageSize         int
+	Cache            CacheConfig
+	Preferences      Preferences
 }
 
 // NewConfig creates a new configuration with default values
@@ -41,6 +93,20 @@ func NewConfig(user, token string) *Config {
 		}
 	}
 
+	detailTTL := 10 * time.Minute
+	if envTTL := os.Getenv("GIST_CACHE_DETAIL_TTL"); envTTL != "" {
+		if seconds, err := strconv.Atoi(envTTL); err == nil {
+			detailTTL = time.Duration(seconds) * time.Second
+		}
+	}
+
+	detailRetention := DefaultDetailRetention
+	if envRetention := os.Getenv("GIST_CACHE_DETAIL_RETENTION"); envRetention != "" {
+		if seconds, err := strconv.Atoi(envRetention); err == nil {
+			detailRetention = time.Duration(seconds) * time.Second
+		}
+	}
+
 	cleanupFreq := time.Hour
 	if envFreq := os.Getenv("GIST_CACHE_CLEANUP_FREQ"); envFreq != "" {
 		if seconds, err := strconv.Atoi(envFreq); err == nil {
@@ -48,12 +114,40 @@ func NewConfig(user, token string) *Config {
 		}
 	}
 
+	fullSyncInterval := 24 * time.Hour
+	if envInterval := os.Getenv("GIST_FULL_SYNC_INTERVAL"); envInterval != "" {
+		if seconds, err := strconv.Atoi(envInterval); err == nil {
+			fullSyncInterval = time.Duration(seconds) * time.Second
+		}
+	}
+
+	backend := CacheBackendFile
+	if envBackend := os.Getenv("GIST_CACHE_BACKEND"); envBackend != "" {
+		backend = envBackend
+	}
+
+	cacheDir := os.Getenv("GIST_CACHE_DIR")
+
+	pageSize := 0 // client default
+	if envPageSize := os.Getenv("GIST_PAGE_SIZE"); envPageSize != "" {
+		if n, err := strconv.Atoi(envPageSize); err == nil {
+			pageSize = n
+		}
+	}
+
 	return &Config{
 		GitHubUser:  user,
 		GitHubToken: token,
+		PageSize:    pageSize,
+		Preferences: Preferences{Output: OutputTable},
 		Cache: CacheConfig{
-			TTL:         ttl,
-			CleanupFreq: cleanupFreq,
+			Dir:              cacheDir,
+			Backend:          backend,
+			TTL:              ttl,
+			DetailTTL:        detailTTL,
+			DetailRetention:  detailRetention,
+			CleanupFreq:      cleanupFreq,
+			FullSyncInterval: fullSyncInterval,
 		},
 	}
 }
@@ -63,10 +157,100 @@ func (c Config) Valid() bool {
 	return c.GitHubUser != "" && c.GitHubToken != ""
 }
 
-// GistFile represents a single file within a gist
+// GistFile represents a single file within a gist.
+//
+// In an update, the key in Gist.Files names the file as it currently exists
+// on GitHub. A Filename different from that key renames the file, and
+// Deleted removes it.
+//
+// GitHub truncates large file contents in gist responses and omits them from
+// list responses; Truncated marks partial content, and RawURL serves the full
+// body.
 type GistFile struct {
-	Content  string `json:"content,omitempty"`
-	Filename string `json:"filename,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+	RawURL    string `json:"raw_url,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Deleted   bool   `json:"-"`
+}
+
+// ChangeStatus summarizes the lines changed by a revision
+type ChangeStatus struct {
+	Total     int `json:"total"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// CacheValidators identify a cached API response so it can be revalidated
+// with a conditional request instead of being downloaded again
+type CacheValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Empty reports whether there is nothing to revalidate with
+func (v CacheValidators) Empty() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// CachedGist is a gist held in the per-gist detail cache
+type CachedGist struct {
+	Gist       *Gist
+	Validators CacheValidators
+	FetchedAt  time.Time
+	Stale      bool
+}
+
+// CacheEntry is one gist held in the cache, either in the gist list or, with
+// Detail set, fully fetched in the detail cache
+type CacheEntry struct {
+	ID          GistID
+	Description string
+	Detail      bool
+	FetchedAt   time.Time
+	Size        int64
+}
+
+// CacheStats summarizes the contents of the cache. Hits and Misses count
+// the gist lookups since CountingSince; a hit was answered from the cache
+// without contacting GitHub.
+type CacheStats struct {
+	Backend       string
+	Dir           string
+	Listed        int
+	Details       int
+	Size          int64
+	FetchedAt     time.Time
+	FullSyncAt    time.Time
+	OldestDetail  time.Time
+	Hits          int
+	Misses        int
+	CountingSince time.Time
+}
+
+// SyncState records when the cached gist list was last brought up to date,
+// incrementally or with a full listing that also drops deleted gists
+type SyncState struct {
+	FetchedAt  time.Time
+	FullSyncAt time.Time
+}
+
+// SyncResult summarizes a sync of the gist cache. Updated counts the gists
+// merged by an incremental sync; a full sync replaces the whole list.
+type SyncResult struct {
+	Gists   []Gist
+	Updated int
+	Full    bool
+}
+
+// GistRevision is one entry in a gist's history
+type GistRevision struct {
+	Version      string       `json:"version"`
+	CommittedAt  time.Time    `json:"committed_at"`
+	ChangeStatus ChangeStatus `json:"change_status"`
 }
 
 // Gist represents a GitHub gist
@@ -78,6 +262,7 @@ type Gist struct {
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
 	HTMLURL     string              `json:"html_url"`
+	History     []GistRevision      `json:"history,omitempty"`
 }
 
 // NewGist creates a new gist with validation
@@ -100,3 +285,20 @@ func (g *Gist) AddFile(filename, content string) {
 	}
 	g.UpdatedAt = time.Now()
 }
+
+// RenameFile renames an existing file, keeping any content already set
+func (g *Gist) RenameFile(oldName, newName string) {
+	file := g.Files[oldName]
+	file.Filename = newName
+	g.Files[oldName] = file
+	g.UpdatedAt = time.Now()
+}
+
+// RemoveFile marks a file for deletion
+func (g *Gist) RemoveFile(filename string) {
+	g.Files[filename] = GistFile{
+		Filename: filename,
+		Deleted:  true,
+	}
+	g.UpdatedAt = time.Now()
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "path/filepath"
+
+// DefaultProfile is the profile used when none is selected. A config file
+// written before profiles existed holds only this profile.
+const DefaultProfile = "default"
+
+// Profile is a named set of GitHub credentials in the config file
+type Profile struct {
+	Name       string
+	GitHubUser string
+	TokenStore string
+	Current    bool
+}
+
+// ValidProfileName reports whether name can be used as a profile name. The
+// name also names the profile's cache directory, so it is limited to
+// letters, digits, dashes and underscores.
+func ValidProfileName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ProfileCacheDir returns the cache directory of a profile under base. The
+// default profile uses base itself, so a cache from before profiles keeps
+// working; other profiles use base/profiles/<name>.
+func ProfileCacheDir(base, profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return base
+	}
+	return filepath.Join(base, "profiles", profile)
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting sources, from the lowest precedence to the highest. Command-line
+// flags override all of them.
+const (
+	SourceDefault = "default"
+	SourceUser    = "user"
+	SourceProject = "project"
+	SourceEnv     = "env"
+)
+
+// Output formats selectable with the output setting
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// Preferences are the settings that shape commands rather than how gist
+// reaches GitHub: the visibility and hashtags of new gists, the editor and
+// the output format of list and show
+type Preferences struct {
+	Public bool
+	Tags   []string
+	Editor string
+	Output string
+}
+
+// SettingKey describes a key of the settings files
+type SettingKey struct {
+	Name string
+	Env  string
+	Doc  string
+	// Project reports whether a project-local file may set the key. Keys
+	// that run a command or decide where files and tokens go are left to
+	// the user's own file, so a checked-out repository cannot set them.
+	Project bool
+	apply   func(c *Config, value string) error
+	value   func(c *Config) string
+}
+
+// SettingValue is the effective value of a key and the layer it came from
+type SettingValue struct {
+	Key    SettingKey
+	Value  string
+	Source string
+}
+
+// SettingKeys lists every supported key in the order they are shown
+var SettingKeys = []SettingKey{
+	{
+		Name: "api_url", Env: "GIST_API_URL",
+		Doc: "GitHub API URL for GITHUB_TOKEN credentials; profiles keep their own",
+		apply: func(c *Config, value string) error {
+			apiURL, err := NormalizeAPIURL(value)
+			if err != nil {
+				return err
+			}
+			c.APIURL = apiURL
+			return nil
+		},
+		value: func(c *Config) string { return c.APIURL },
+	},
+	{
+		Name: "editor", Env: "GIST_EDITOR",
+		Doc: "editor command; defaults to $VISUAL, $EDITOR, then vi",
+		apply: func(c *Config, value string) error {
+			c.Preferences.Editor = value
+			return nil
+		},
+		value: func(c *Config) string { return c.Preferences.Editor },
+	},
+	{
+		Name: "output", Env: "GIST_OUTPUT", Project: true,
+		Doc: "output format of list and show: table or json",
+		apply: func(c *Config, value string) error {
+			switch value {
+			case OutputTable, OutputJSON:
+				c.Preferences.Output = value
+				return nil
+			}
+			return ErrInvalidSetting{Key: "output", Value: value, Reason: "use table or json"}
+		},
+		value: func(c *Config) string { return c.Preferences.Output },
+	},
+	{
+		Name: "defaults.visibility", Env: "GIST_VISIBILITY", Project: true,
+		Doc: "visibility of new gists: public or private",
+		apply: func(c *Config, value string) error {
+			switch value {
+			case "public", "private":
+				c.Preferences.Public = value == "public"
+				return nil
+			}
+			return ErrInvalidSetting{Key: "defaults.visibility", Value: value, Reason: "use public or private"}
+		},
+		value: func(c *Config) string {
+			if c.Preferences.Public {
+				return "public"
+			}
+			return "private"
+		},
+	},
+	{
+		Name: "defaults.tags", Env: "GIST_TAGS", Project: true,
+		Doc: "hashtags added to the description of new gists, comma separated",
+		apply: func(c *Config, value string) error {
+			tags, err := ParseTagList(value)
+			if err != nil {
+				return err
+			}
+			c.Preferences.Tags = tags
+			return nil
+		},
+		value: func(c *Config) string { return strings.Join(c.Preferences.Tags, ", ") },
+	},
+	{
+		Name: "cache.ttl", Env: "GIST_CACHE_TTL", Project: true,
+		Doc: "how long the cached gist list is fresh, e.g. 5m, or seconds",
+		apply: func(c *Config, value string) error {
+			ttl, err := parseSeconds(value)
+			if err != nil {
+				return ErrInvalidSetting{Key: "cache.ttl", Value: value, Reason: "use a duration such as 5m or a number of seconds"}
+			}
+			c.Cache.TTL = ttl
+			return nil
+		},
+		value: func(c *Config) string { return c.Cache.TTL.String() },
+	},
+	{
+		Name: "cache.dir", Env: "GIST_CACHE_DIR",
+		Doc: "cache directory; defaults to ~/.gist-cache",
+		apply: func(c *Config, value string) error {
+			if !filepath.IsAbs(value) {
+				return ErrInvalidSetting{Key: "cache.dir", Value: value, Reason: "use an absolute path"}
+			}
+			c.Cache.Dir = value
+			return nil
+		},
+		value: func(c *Config) string { return c.Cache.Dir },
+	},
+}
+
+// LookupSetting returns the key called name
+func LookupSetting(name string) (SettingKey, error) {
+	for _, key := range SettingKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return SettingKey{}, ErrUnknownSetting{Key: name}
+}
+
+// Apply sets the key on config, checking the value
+func (k SettingKey) Apply(config *Config, value string) error {
+	return k.apply(config, value)
+}
+
+// Value returns the key's value in config
+func (k SettingKey) Value(config *Config) string {
+	return k.value(config)
+}
+
+// Check reports whether value is valid for the key
+func (k SettingKey) Check(value string) error {
+	return k.apply(NewConfig("", ""), value)
+}
+
+// ParseTagList reads a comma or space separated list of hashtags, with or
+// without their leading #
+func ParseTagList(value string) ([]string, error) {
+	var tags []string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tag := strings.TrimPrefix(field, "#")
+		if tag == "" || strings.Contains(tag, "#") {
+			return nil, ErrInvalidSetting{Key: "defaults.tags", Value: value, Reason: "use words separated by commas"}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// WithTags appends the hashtags in tags missing from description
+func WithTags(description string, tags []string) string {
+	if len(tags) == 0 {
+		return description
+	}
+	return MergeTags(description, "#"+strings.Join(tags, " #"))
+}
+
+// parseSeconds reads a duration, or a bare number as seconds as the
+// environment variables always have
+func parseSeconds(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// StagedFile is a snapshot of a local file recorded in the staging area
+type StagedFile struct {
+	Path     string    `json:"path"`
+	Filename string    `json:"filename"`
+	Content  string    `json:"content"`
+	Hash     string    `json:"hash"`
+	StagedAt time.Time `json:"staged_at"`
+}
+
+// StageState describes a staged file relative to its working copy
+type StageState string
+
+const (
+	// StageClean means the working copy matches the staged snapshot
+	StageClean StageState = "staged"
+	// StageModified means the working copy changed after it was staged
+	StageModified StageState = "modified"
+	// StageMissing means the working copy no longer exists
+	StageMissing StageState = "missing"
+)
+
+// StagedFileStatus pairs a staged file with the state of its working copy
+type StagedFileStatus struct {
+	StagedFile
+	State StageState
+}
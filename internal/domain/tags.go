@@ -0,0 +1,42 @@
+package domain
+
+import "strings"
+
+// ExtractTags extracts hashtags from a description
+func ExtractTags(description string) []string {
+	var tags []string
+	words := strings.Fields(description)
+
+	for _, word := range words {
+		if strings.HasPrefix(word, "#") && len(word) > 1 {
+			tag := strings.TrimPrefix(word, "#")
+			// Remove trailing punctuation
+			tag = strings.TrimRight(tag, ".,!?;:")
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// MergeTags appends the hashtags of previous that are missing from
+// description, so replacing a title does not drop a post's tags
+func MergeTags(description, previous string) string {
+	present := make(map[string]bool)
+	for _, tag := range ExtractTags(description) {
+		present[strings.ToLower(tag)] = true
+	}
+
+	merged := strings.TrimSpace(description)
+	for _, tag := range ExtractTags(previous) {
+		if present[strings.ToLower(tag)] {
+			continue
+		}
+		present[strings.ToLower(tag)] = true
+		merged += " #" + tag
+	}
+
+	return strings.TrimSpace(merged)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// WorkingCopyFile is the metadata file that marks a directory as a gist
+// working copy
+const WorkingCopyFile = ".gist.json"
+
+// WorkingCopy records which gist a local directory mirrors and the state of
+// each file as of the last clone or pull
+type WorkingCopy struct {
+	ID          GistID            `json:"id"`
+	Description string            `json:"description"`
+	Public      bool              `json:"public"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Files       map[string]string `json:"files"`
+}
+
+// NewWorkingCopy records the given gist as the synced state
+func NewWorkingCopy(gist *Gist) *WorkingCopy {
+	wc := &WorkingCopy{
+		ID:          gist.ID,
+		Description: gist.Description,
+		Public:      gist.Public,
+		UpdatedAt:   gist.UpdatedAt,
+		Files:       make(map[string]string),
+	}
+	for name, file := range gist.Files {
+		wc.Files[name] = HashContent([]byte(file.Content))
+	}
+	return wc
+}
+
+// HashContent returns the hex SHA-256 of content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gist/internal/domain"
+)
+
+// AuthService checks the configured GitHub credentials
+type AuthService struct {
+	authRepo AuthRepository
+	config   *domain.Config
+}
+
+// NewAuthService creates a new auth service with injected dependencies
+func NewAuthService(authRepo AuthRepository, config *domain.Config) *AuthService {
+	return &AuthService{
+		authRepo: authRepo,
+		config:   config,
+	}
+}
+
+// AuthStatus asks GitHub who the token belongs to and checks it against
+// the config: the login must match the configured user and the token must
+// have the gist scope. When the check fails the status is returned along
+// with the error, so callers can still show what GitHub reported.
+func (s *AuthService) AuthStatus(ctx context.Context) (*domain.AuthStatus, error) {
+	status, err := s.authRepo.GetAuthStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check token: %w", err)
+	}
+
+	if !strings.EqualFold(status.Login, s.config.GitHubUser) {
+		return status, domain.ErrLoginMismatch{Configured: s.config.GitHubUser, Login: status.Login}
+	}
+	if status.ScopesKnown && !status.HasScope(domain.GistScope) {
+		return status, domain.ErrMissingScope{Scope: domain.GistScope}
+	}
+
+	return status, nil
+}
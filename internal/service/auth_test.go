@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+type fakeAuthRepo struct {
+	status *domain.AuthStatus
+	err    error
+}
+
+func (f *fakeAuthRepo) GetAuthStatus(ctx context.Context) (*domain.AuthStatus, error) {
+	return f.status, f.err
+}
+
+func TestAuthService_AuthStatus(t *testing.T) {
+	config := domain.NewConfig("Octocat", "tok")
+
+	tests := map[string]struct {
+		status  *domain.AuthStatus
+		repoErr error
+		wantErr any
+	}{
+		"valid": {
+			status: &domain.AuthStatus{Login: "octocat", Scopes: []string{"gist", "repo"}, ScopesKnown: true},
+		},
+		"fine-grained token": {
+			status: &domain.AuthStatus{Login: "octocat"},
+		},
+		"other account": {
+			status:  &domain.AuthStatus{Login: "someone", Scopes: []string{"gist"}, ScopesKnown: true},
+			wantErr: &domain.ErrLoginMismatch{},
+		},
+		"missing scope": {
+			status:  &domain.AuthStatus{Login: "octocat", Scopes: []string{"repo"}, ScopesKnown: true},
+			wantErr: &domain.ErrMissingScope{},
+		},
+		"no scopes": {
+			status:  &domain.AuthStatus{Login: "octocat", ScopesKnown: true},
+			wantErr: &domain.ErrMissingScope{},
+		},
+		"bad credentials": {
+			repoErr: domain.ErrBadCredentials{},
+			wantErr: &domain.ErrBadCredentials{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := NewAuthService(&fakeAuthRepo{status: tt.status, err: tt.repoErr}, config)
+
+			status, err := svc.AuthStatus(context.Background())
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("AuthStatus: %v", err)
+				}
+				if status != tt.status {
+					t.Errorf("expected the repository status, got %+v", status)
+				}
+				return
+			}
+
+			if !errors.As(err, tt.wantErr) {
+				t.Fatalf("expected %T, got %v", tt.wantErr, err)
+			}
+			if tt.repoErr == nil && status != tt.status {
+				t.Errorf("expected the status alongside the error, got %+v", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// BackupService backs up and restores every gist of an account
+type BackupService struct {
+	gistRepo GistRepository
+	archives ArchiveRepository
+	config   *domain.Config
+}
+
+// NewBackupService creates a new backup service with injected dependencies
+func NewBackupService(
+	gistRepo GistRepository,
+	archives ArchiveRepository,
+	config *domain.Config,
+) *BackupService {
+	return &BackupService{
+		gistRepo: gistRepo,
+		archives: archives,
+		config:   config,
+	}
+}
+
+// BackupGists writes every gist of the account, with full file contents, to
+// a new archive at location along with a JSON manifest. Truncated files are
+// downloaded from their raw URL so the backup never holds partial content.
+func (s *BackupService) BackupGists(ctx context.Context, location string) (*domain.BackupManifest, error) {
+	gists, err := s.gistRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch gists: %w", err)
+	}
+
+	out, err := s.archives.Create(location)
+	if err != nil {
+		return nil, fmt.Errorf("create archive: %w", err)
+	}
+
+	manifest := &domain.BackupManifest{
+		CreatedAt: time.Now().UTC(),
+		User:      s.config.GitHubUser,
+	}
+
+	for _, listed := range gists {
+		gist, err := fetchFullGist(ctx, s.gistRepo, listed.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get gist %s: %w", listed.ID, err)
+		}
+
+		entry := domain.BackupEntry{
+			ID:          gist.ID,
+			Description: gist.Description,
+			Public:      gist.Public,
+			CreatedAt:   gist.CreatedAt,
+			UpdatedAt:   gist.UpdatedAt,
+			HTMLURL:     gist.HTMLURL,
+		}
+		for name, file := range gist.Files {
+			if err := checkBackupName(name); err != nil {
+				return nil, fmt.Errorf("gist %s: %w", gist.ID, err)
+			}
+			if err := out.WriteFile(domain.BackupFilePath(gist.ID, name), []byte(file.Content)); err != nil {
+				return nil, fmt.Errorf("write %s of gist %s: %w", name, gist.ID, err)
+			}
+			entry.Files = append(entry.Files, name)
+		}
+		sort.Strings(entry.Files)
+
+		manifest.Gists = append(manifest.Gists, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := out.WriteFile(domain.BackupManifestFile, data); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("finalize archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// RestoreBackup recreates the gists of the archive at location that are
+// missing from the account. A gist is considered present when its ID exists
+// on the account or a gist with the same description and filenames does, so
+// restoring twice does not create duplicates. Failures are reported per gist.
+func (s *BackupService) RestoreBackup(ctx context.Context, location string) ([]domain.RestoreResult, error) {
+	in, err := s.archives.Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	data, err := in.ReadFile(domain.BackupManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var manifest domain.BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	existing, err := s.gistRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch gists: %w", err)
+	}
+	byID := make(map[domain.GistID]bool)
+	bySignature := make(map[string]domain.GistID)
+	for _, gist := range existing {
+		byID[gist.ID] = true
+		var names []string
+		for name := range gist.Files {
+			names = append(names, name)
+		}
+		bySignature[gistSignature(gist.Description, names)] = gist.ID
+	}
+
+	var results []domain.RestoreResult
+	for _, entry := range manifest.Gists {
+		result := domain.RestoreResult{OldID: entry.ID}
+
+		if byID[entry.ID] {
+			result.NewID, result.Status = entry.ID, domain.RestoreExists
+			results = append(results, result)
+			continue
+		}
+		if id, ok := bySignature[gistSignature(entry.Description, entry.Files)]; ok {
+			result.NewID, result.Status = id, domain.RestoreExists
+			results = append(results, result)
+			continue
+		}
+
+		newID, err := s.restoreEntry(ctx, in, entry)
+		if err != nil {
+			result.Status, result.Err = domain.RestoreFailed, err
+		} else {
+			result.NewID, result.Status = newID, domain.RestoreCreated
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// restoreEntry recreates a single backed-up gist and returns its new ID
+func (s *BackupService) restoreEntry(ctx context.Context, in ArchiveReader, entry domain.BackupEntry) (domain.GistID, error) {
+	if err := checkBackupName(string(entry.ID)); err != nil {
+		return "", err
+	}
+
+	gist := domain.NewGist("", entry.Description, entry.Public)
+	for _, name := range entry.Files {
+		if err := checkBackupName(name); err != nil {
+			return "", err
+		}
+		content, err := in.ReadFile(domain.BackupFilePath(entry.ID, name))
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", name, err)
+		}
+		gist.AddFile(name, string(content))
+	}
+
+	if err := s.gistRepo.Create(ctx, gist); err != nil {
+		return "", fmt.Errorf("create gist: %w", err)
+	}
+
+	return gist.ID, nil
+}
+
+// gistSignature identifies a gist by description and filenames
+func gistSignature(description string, filenames []string) string {
+	names := append([]string(nil), filenames...)
+	sort.Strings(names)
+	return description + "\x00" + strings.Join(names, "\x00")
+}
+
+// checkBackupName rejects names that would escape their directory in a
+// backup
+func checkBackupName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || path.Clean(name) != name {
+		return fmt.Errorf("unsafe filename %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+// fakeArchive is an in-memory ArchiveRepository holding a single archive
+type fakeArchive struct {
+	files  map[string][]byte
+	closed bool
+}
+
+func (f *fakeArchive) Create(string) (ArchiveWriter, error) {
+	f.files = make(map[string][]byte)
+	return f, nil
+}
+func (f *fakeArchive) Open(string) (ArchiveReader, error) {
+	if f.files == nil {
+		return nil, os.ErrNotExist
+	}
+	return f, nil
+}
+func (f *fakeArchive) WriteFile(name string, content []byte) error {
+	f.files[name] = content
+	return nil
+}
+func (f *fakeArchive) ReadFile(name string) ([]byte, error) {
+	content, ok := f.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+func (f *fakeArchive) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestBackupGists_DownloadsTruncatedFiles(t *testing.T) {
+	full := domain.NewGist("g1", "notes", true)
+	full.AddFile("a.md", "short")
+	full.Files["big.log"] = domain.GistFile{Filename: "big.log", Content: "partial", RawURL: "https://raw/big.log", Truncated: true}
+	repo := &fakeRepo{
+		all:   []domain.Gist{{ID: "g1"}},
+		byIDs: map[domain.GistID]*domain.Gist{"g1": full},
+		raw:   map[string]string{"https://raw/big.log": "the whole log"},
+	}
+	archive := &fakeArchive{}
+	svc := NewBackupService(repo, archive, &domain.Config{GitHubUser: "octo"})
+
+	manifest, err := svc.BackupGists(context.Background(), "out.tar.gz")
+	if err != nil {
+		t.Fatalf("BackupGists: %v", err)
+	}
+	if !archive.closed {
+		t.Error("expected archive to be closed")
+	}
+	if len(manifest.Gists) != 1 || len(manifest.Gists[0].Files) != 2 || manifest.User != "octo" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if got := string(archive.files[domain.BackupFilePath("g1", "big.log")]); got != "the whole log" {
+		t.Errorf("expected full content of truncated file, got %q", got)
+	}
+	if got := string(archive.files[domain.BackupFilePath("g1", "a.md")]); got != "short" {
+		t.Errorf("unexpected content %q", got)
+	}
+
+	var stored domain.BackupManifest
+	if err := json.Unmarshal(archive.files[domain.BackupManifestFile], &stored); err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	if len(stored.Gists) != 1 || stored.Gists[0].ID != "g1" {
+		t.Errorf("unexpected stored manifest: %+v", stored)
+	}
+}
+
+func TestBackupGists_RejectsUnsafeFilename(t *testing.T) {
+	g := domain.NewGist("g1", "", true)
+	g.AddFile("../escape", "x")
+	repo := &fakeRepo{all: []domain.Gist{{ID: "g1"}}, byID: g}
+	svc := NewBackupService(repo, &fakeArchive{}, &domain.Config{})
+
+	if _, err := svc.BackupGists(context.Background(), "out"); err == nil {
+		t.Fatal("expected an error for an unsafe filename")
+	}
+}
+
+func TestRestoreBackup_CreatesMissingAndSkipsExisting(t *testing.T) {
+	archive := &fakeArchive{files: make(map[string][]byte)}
+	manifest := domain.BackupManifest{Gists: []domain.BackupEntry{
+		{ID: "same", Description: "kept", Files: []string{"a.md"}},
+		{ID: "old1", Description: "copy", Files: []string{"b.md"}},
+		{ID: "old2", Description: "new", Public: true, Files: []string{"c.md"}},
+		{ID: "old3", Description: "broken", Files: []string{"missing.md"}},
+	}}
+	data, _ := json.Marshal(manifest)
+	archive.files[domain.BackupManifestFile] = data
+	archive.files[domain.BackupFilePath("old2", "c.md")] = []byte("restored")
+
+	existing := []domain.Gist{
+		{ID: "same", Files: map[string]domain.GistFile{"x.md": {}}},
+		{ID: "other", Description: "copy", Files: map[string]domain.GistFile{"b.md": {}}},
+	}
+	repo := &fakeRepo{all: existing}
+	svc := NewBackupService(repo, archive, &domain.Config{})
+
+	results, err := svc.RestoreBackup(context.Background(), "in")
+	if err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %+v", results)
+	}
+
+	want := []struct {
+		newID  domain.GistID
+		status domain.RestoreStatus
+	}{
+		{"same", domain.RestoreExists},
+		{"other", domain.RestoreExists},
+		{"newgistid123", domain.RestoreCreated},
+		{"", domain.RestoreFailed},
+	}
+	for i, w := range want {
+		if results[i].NewID != w.newID || results[i].Status != w.status {
+			t.Errorf("result %d: got %+v, want %s/%s", i, results[i], w.newID, w.status)
+		}
+	}
+	if !errors.Is(results[3].Err, os.ErrNotExist) {
+		t.Errorf("expected missing file error, got %v", results[3].Err)
+	}
+
+	if len(repo.created) != 1 {
+		t.Fatalf("expected one gist created, got %d", len(repo.created))
+	}
+	created := repo.created[0]
+	if created.Description != "new" || !created.Public || created.Files["c.md"].Content != "restored" {
+		t.Errorf("unexpected created gist: %+v", created)
+	}
+}
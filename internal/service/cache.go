@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// CacheService inspects and maintains the local gist cache
+type CacheService struct {
+	cacheRepo CacheRepository
+}
+
+// NewCacheService creates a new cache service with injected dependencies
+func NewCacheService(cacheRepo CacheRepository) *CacheService {
+	return &CacheService{cacheRepo: cacheRepo}
+}
+
+// CacheStats summarizes the cache and restarts the lookup counters, so each
+// call reports the hits and misses since the previous one
+func (s *CacheService) CacheStats() (*domain.CacheStats, error) {
+	stats, err := s.cacheRepo.Stats()
+	if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+
+	if err := s.cacheRepo.ResetCounters(); err != nil {
+		fmt.Printf("Warning: failed to reset cache counters: %v\n", err)
+	}
+
+	return stats, nil
+}
+
+// CacheEntries lists the cached gists
+func (s *CacheService) CacheEntries() ([]domain.CacheEntry, error) {
+	entries, err := s.cacheRepo.Entries()
+	if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+	return entries, nil
+}
+
+// PruneCache removes the cache entries fetched more than olderThan ago and
+// returns how many were removed
+func (s *CacheService) PruneCache(olderThan time.Duration) (int, error) {
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("prune age must be positive, got %s", olderThan)
+	}
+
+	removed, err := s.cacheRepo.Prune(time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("prune cache: %w", err)
+	}
+	return removed, nil
+}
+
+// ClearCache removes all cached data
+func (s *CacheService) ClearCache() error {
+	if err := s.cacheRepo.Clear(); err != nil {
+		return fmt.Errorf("clear cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+func TestCacheStats_ResetsCounters(t *testing.T) {
+	cache := &fakeCache{stats: domain.CacheStats{Listed: 3, Hits: 2, Misses: 1}}
+	svc := NewCacheService(cache)
+
+	stats, err := svc.CacheStats()
+	if err != nil {
+		t.Fatalf("CacheStats: %v", err)
+	}
+	if stats.Listed != 3 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !cache.reset {
+		t.Error("expected counters reset after reporting them")
+	}
+}
+
+func TestPruneCache_Cutoff(t *testing.T) {
+	cache := &fakeCache{entries: []domain.CacheEntry{{ID: "a"}, {ID: "b"}}}
+	svc := NewCacheService(cache)
+
+	before := time.Now()
+	removed, err := svc.PruneCache(time.Hour)
+	if err != nil {
+		t.Fatalf("PruneCache: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 removed, got %d", removed)
+	}
+	if cache.prunedBefore.Before(before.Add(-time.Hour)) || cache.prunedBefore.After(time.Now().Add(-time.Hour)) {
+		t.Errorf("unexpected cutoff %v", cache.prunedBefore)
+	}
+
+	if _, err := svc.PruneCache(0); err == nil {
+		t.Error("expected a non-positive age to be rejected")
+	}
+}
+
+func TestLookups_CountHitsAndMisses(t *testing.T) {
+	gist := &domain.Gist{ID: "abc", Files: map[string]domain.GistFile{}}
+	cache := &fakeCache{
+		gists: []domain.Gist{*gist},
+		details: map[domain.GistID]*domain.CachedGist{
+			"abc": {Gist: gist, FetchedAt: time.Now()},
+		},
+	}
+	repo := &fakeRepo{all: []domain.Gist{*gist}, byID: gist}
+	svc := newSvc(repo, cache, &fakeFS{})
+	ctx := context.Background()
+
+	if _, err := svc.ListGists(ctx); err != nil {
+		t.Fatalf("ListGists: %v", err)
+	}
+	if _, err := svc.GetGist(ctx, "abc"); err != nil {
+		t.Fatalf("GetGist: %v", err)
+	}
+	if cache.hits != 0 || cache.misses != 0 {
+		t.Errorf("expected lookups counted in memory until flushed, got %d hits %d misses", cache.hits, cache.misses)
+	}
+	if err := svc.FlushLookups(); err != nil {
+		t.Fatalf("FlushLookups: %v", err)
+	}
+	if cache.hits != 2 || cache.misses != 0 {
+		t.Errorf("expected 2 hits from a fresh cache, got %d hits %d misses", cache.hits, cache.misses)
+	}
+
+	cache.stale = true
+	cache.details["abc"].Stale = true
+	if _, err := svc.ListGists(ctx); err != nil {
+		t.Fatalf("ListGists: %v", err)
+	}
+	if _, err := svc.GetGist(ctx, "abc"); err != nil {
+		t.Fatalf("GetGist: %v", err)
+	}
+	if err := svc.FlushLookups(); err != nil {
+		t.Fatalf("FlushLookups: %v", err)
+	}
+	if cache.hits != 2 || cache.misses != 2 {
+		t.Errorf("expected 2 misses from a stale cache, got %d hits %d misses", cache.hits, cache.misses)
+	}
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
 
+	"gist/internal/diff"
 	"gist/internal/domain"
 )
 
@@ -16,20 +19,31 @@ const maxGistFileSize = 1 * 1024 * 1024
 type GistService struct {
 	gistRepo  GistRepository
 	cacheRepo CacheRepository
+	staging   StagingRepository
+	workCopy  WorkingCopyRepository
 	fs        FileSystem
 	config    *domain.Config
+
+	// Cache lookups counted since the last FlushLookups
+	lookupMu sync.Mutex
+	hits     int
+	misses   int
 }
 
 // NewGistService creates a new gist service with injected dependencies
 func NewGistService(
 	gistRepo GistRepository,
 	cacheRepo CacheRepository,
+	staging StagingRepository,
+	workCopy WorkingCopyRepository,
 	fs FileSystem,
 	config *domain.Config,
 ) *GistService {
 	return &GistService{
 		gistRepo:  gistRepo,
 		cacheRepo: cacheRepo,
+		staging:   staging,
+		workCopy:  workCopy,
 		fs:        fs,
 		config:    config,
 	}
@@ -37,22 +51,12 @@ func NewGistService(
 
 // PublishFiles creates a gist directly from files
 func (s *GistService) PublishFiles(ctx context.Context, paths []string, description string, public bool) (string, error) {
-	// Validate files exist and are within the size limit
-	for _, path := range paths {
-		if !s.fs.Exists(path) {
-			return "", domain.ErrFileNotFound{Path: path}
-		}
-		size, err := s.fs.Size(path)
-		if err != nil {
-			return "", fmt.Errorf("stat file %s: %w", path, err)
-		}
-		if size > maxGistFileSize {
-			return "", fmt.Errorf("file %s is too large: %d bytes (max %d)", path, size, maxGistFileSize)
-		}
+	if err := s.validateFiles(paths); err != nil {
+		return "", err
 	}
 
 	// Create gist with files
-	gist := domain.NewGist("", description, public)
+	gist := domain.NewGist("", domain.WithTags(description, s.config.Preferences.Tags), public)
 
 	for _, path := range paths {
 		content, err := s.fs.ReadFile(path)
@@ -72,23 +76,137 @@ func (s *GistService) PublishFiles(ctx context.Context, paths []string, descript
 	return string(gist.ID), nil
 }
 
-// ListGists retrieves all gists, using cache when possible
+// Preferences returns the settings that shape commands, such as the
+// default visibility of new gists
+func (s *GistService) Preferences() domain.Preferences {
+	return s.config.Preferences
+}
+
+// validateFiles checks that files exist and are within the size limit
+func (s *GistService) validateFiles(paths []string) error {
+	for _, path := range paths {
+		if !s.fs.Exists(path) {
+			return domain.ErrFileNotFound{Path: path}
+		}
+		size, err := s.fs.Size(path)
+		if err != nil {
+			return fmt.Errorf("stat file %s: %w", path, err)
+		}
+		if size > maxGistFileSize {
+			return fmt.Errorf("file %s is too large: %d bytes (max %d)", path, size, maxGistFileSize)
+		}
+	}
+	return nil
+}
+
+// PrepareUpdate compares local files against an existing gist and returns the
+// update to push along with a per-file summary. Files are matched by base
+// name; files missing from the gist are added. A non-empty description
+// replaces the current one, keeping any hashtags it does not repeat.
+func (s *GistService) PrepareUpdate(ctx context.Context, id string, paths []string, description string) (*domain.Gist, []domain.FileChange, error) {
+	gistID := domain.GistID(id)
+	if !gistID.Valid() {
+		return nil, nil, domain.ErrInvalidGistID{ID: id}
+	}
+
+	if err := s.validateFiles(paths); err != nil {
+		return nil, nil, err
+	}
+
+	current, err := fetchFullGist(ctx, s.gistRepo, gistID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get gist %s: %w", id, err)
+	}
+
+	update := &domain.Gist{
+		ID:          current.ID,
+		Description: current.Description,
+		Public:      current.Public,
+		Files:       make(map[string]domain.GistFile),
+	}
+	if description != "" {
+		update.Description = domain.MergeTags(description, current.Description)
+	}
+
+	var changes []domain.FileChange
+	seen := make(map[string]string)
+	for _, path := range paths {
+		filename := filepath.Base(path)
+		if other, ok := seen[filename]; ok {
+			return nil, nil, fmt.Errorf("files %s and %s both map to %s", other, path, filename)
+		}
+		seen[filename] = path
+
+		content, err := s.fs.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read file %s: %w", path, err)
+		}
+
+		change := domain.FileChange{Filename: filename, Status: domain.FileAdded}
+		old, exists := current.Files[filename]
+		if exists {
+			change.Status = domain.FileModified
+		}
+		change.LinesAdded, change.LinesRemoved = diff.Stat(diff.Lines(old.Content, string(content)))
+
+		if exists && old.Content == string(content) {
+			change.Status = domain.FileUnchanged
+		} else {
+			update.AddFile(filename, string(content))
+		}
+		changes = append(changes, change)
+	}
+
+	return update, changes, nil
+}
+
+// ListGists retrieves all gists, using cache when possible. A stale cache is
+// revalidated with a conditional request, so an unchanged list is not
+// downloaded again. When GitHub cannot be reached, the stale cache is
+// returned with a warning.
 func (s *GistService) ListGists(ctx context.Context) ([]domain.Gist, error) {
+	cached, cacheErr := s.cacheRepo.GetGists()
+	hasCache := cacheErr == nil && len(cached) > 0
+
 	// Try cache first
-	if !s.cacheRepo.IsStale() {
-		if gists, err := s.cacheRepo.GetGists(); err == nil && len(gists) > 0 {
-			return gists, nil
-		}
+	fresh := hasCache && !s.cacheRepo.IsStale()
+	s.recordLookup(fresh)
+	if fresh {
+		return cached, nil
+	}
+
+	gists, err := s.fetchList(ctx, cached, hasCache)
+	if err != nil && hasCache && unreachable(err) {
+		warnStale(err, "gists", s.cacheRepo.GetSyncState().FetchedAt)
+		return cached, nil
+	}
+	return gists, err
+}
+
+// fetchList downloads the full gist list and saves it to the cache. With
+// revalidate set, the request is conditional and cached is returned if the
+// list did not change.
+func (s *GistService) fetchList(ctx context.Context, cached []domain.Gist, revalidate bool) ([]domain.Gist, error) {
+	var validators domain.CacheValidators
+	if revalidate {
+		validators = s.cacheRepo.GetValidators()
 	}
 
 	// Fetch from GitHub
-	gists, err := s.gistRepo.GetAll(ctx)
+	gists, validators, err := s.gistRepo.GetAllIfModified(ctx, validators)
+	var notModified domain.ErrNotModified
+	if errors.As(err, &notModified) {
+		if err := s.cacheRepo.Touch(); err != nil {
+			fmt.Printf("Warning: failed to refresh cache: %v\n", err)
+		}
+		return cached, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("fetch gists: %w", err)
 	}
 
 	// Update cache
-	if err := s.cacheRepo.SaveGists(gists); err != nil {
+	if err := s.cacheRepo.SaveGists(gists, validators); err != nil {
 		// Log error but don't fail the operation
 		fmt.Printf("Warning: failed to cache gists: %v\n", err)
 	}
@@ -96,22 +214,151 @@ func (s *GistService) ListGists(ctx context.Context) ([]domain.Gist, error) {
 	return gists, nil
 }
 
-// SyncGists forces a refresh of gists from GitHub
-func (s *GistService) SyncGists(ctx context.Context) ([]domain.Gist, error) {
-	// Clear cache to force refresh
-	_ = s.cacheRepo.Clear()
-
-	// Fetch fresh data from GitHub
-	return s.ListGists(ctx)
-}
-
-// GetGist retrieves a specific gist by ID
+// GetGist retrieves a specific gist by ID. Files GitHub truncated are
+// downloaded in full, so callers always see complete content. A fresh copy in
+// the detail cache is used as-is; a stale one is revalidated with a
+// conditional request, or returned with a warning when GitHub cannot be
+// reached.
 func (s *GistService) GetGist(ctx context.Context, id string) (*domain.Gist, error) {
 	gistID := domain.GistID(id)
 	if !gistID.Valid() {
 		return nil, domain.ErrInvalidGistID{ID: id}
 	}
 
-	return s.gistRepo.GetByID(ctx, gistID)
+	cached, err := s.cacheRepo.GetGistDetail(gistID)
+	fresh := err == nil && !cached.Stale
+	s.recordLookup(fresh)
+	if fresh {
+		return cached.Gist, nil
+	}
+
+	var validators domain.CacheValidators
+	if err == nil {
+		validators = cached.Validators
+	}
+
+	gist, validators, err := s.gistRepo.GetByIDIfModified(ctx, gistID, validators)
+	var notModified domain.ErrNotModified
+	if errors.As(err, &notModified) {
+		if err := s.cacheRepo.TouchGistDetail(gistID); err != nil {
+			fmt.Printf("Warning: failed to refresh cached gist: %v\n", err)
+		}
+		return cached.Gist, nil
+	}
+	if err == nil {
+		err = completeTruncatedFiles(ctx, s.gistRepo, gist)
+	}
+	if err != nil {
+		if unreachable(err) {
+			if cached == nil {
+				return nil, fmt.Errorf("gist %s is not cached: %w", id, err)
+			}
+			warnStale(err, "gist "+id, cached.FetchedAt)
+			return cached.Gist, nil
+		}
+		return nil, err
+	}
+
+	if err := s.cacheRepo.SaveGistDetail(*gist, validators); err != nil {
+		fmt.Printf("Warning: failed to cache gist: %v\n", err)
+	}
+
+	return gist, nil
+}
+
+// recordLookup counts a lookup answered from the cache alone as a hit and
+// any other as a miss. The counts are kept in memory so reads do not write
+// to the cache; FlushLookups saves them.
+func (s *GistService) recordLookup(hit bool) {
+	s.lookupMu.Lock()
+	defer s.lookupMu.Unlock()
+	if hit {
+		s.hits++
+	} else {
+		s.misses++
+	}
+}
+
+// FlushLookups adds the cache hits and misses counted by this service to
+// the cache's counters, once per process as it exits. The counters are
+// informational, so callers may ignore the error.
+func (s *GistService) FlushLookups() error {
+	s.lookupMu.Lock()
+	defer s.lookupMu.Unlock()
+	if s.hits == 0 && s.misses == 0 {
+		return nil
+	}
+	if err := s.cacheRepo.RecordLookups(s.hits, s.misses); err != nil {
+		return err
+	}
+	s.hits, s.misses = 0, 0
+	return nil
+}
+
+// fetchFullGist fetches a gist and completes any truncated files
+func fetchFullGist(ctx context.Context, repo GistRepository, id domain.GistID) (*domain.Gist, error) {
+	gist, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := completeTruncatedFiles(ctx, repo, gist); err != nil {
+		return nil, err
+	}
+	return gist, nil
+}
+
+// completeTruncatedFiles replaces the content of every truncated file with
+// the full body from its raw URL
+func completeTruncatedFiles(ctx context.Context, repo GistRepository, gist *domain.Gist) error {
+	for name, file := range gist.Files {
+		if !file.Truncated || file.RawURL == "" {
+			continue
+		}
+		content, err := repo.GetRawContent(ctx, file.RawURL)
+		if err != nil {
+			return fmt.Errorf("get full content of %s: %w", name, err)
+		}
+		file.Content = content
+		file.Truncated = false
+		gist.Files[name] = file
+	}
+	return nil
 }
 
+// UpdateGist pushes changes to an existing gist in a single request. Only the
+// files in gist.Files are changed; on success gist holds the updated remote
+// state and the cached copy is refreshed.
+func (s *GistService) UpdateGist(ctx context.Context, gist *domain.Gist) error {
+	if !gist.ID.Valid() {
+		return domain.ErrInvalidGistID{ID: string(gist.ID)}
+	}
+
+	if err := s.gistRepo.Update(ctx, gist); err != nil {
+		return fmt.Errorf("update gist %s: %w", gist.ID, err)
+	}
+
+	if err := s.cacheRepo.PutGist(*gist); err != nil {
+		fmt.Printf("Warning: failed to update cache: %v\n", err)
+	}
+
+	return nil
+}
+
+// DeleteGist deletes a gist on GitHub and drops it from the local cache
+func (s *GistService) DeleteGist(ctx context.Context, id string) error {
+	gistID := domain.GistID(id)
+	if !gistID.Valid() {
+		return domain.ErrInvalidGistID{ID: id}
+	}
+
+	if err := s.gistRepo.Delete(ctx, gistID); err != nil {
+		return fmt.Errorf("delete gist %s: %w", id, err)
+	}
+
+	if err := s.cacheRepo.RemoveGist(gistID); err != nil {
+		// The gist is gone remotely; a stale cache entry is only cosmetic
+		fmt.Printf("Warning: failed to update cache: %v\n", err)
+	}
+
+	return nil
+}
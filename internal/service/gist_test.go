@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"gist/internal/domain"
 )
@@ -20,13 +22,50 @@ type fakeRepo struct {
 	createErr    error
 	created      []*domain.Gist
 	getAllCalled bool
+	deleteErr    error
+	deleted      []domain.GistID
+	updateErr    error
+	updated      []*domain.Gist
+	updateResult *domain.Gist
+	history      []domain.GistRevision
+	revisions    map[string]*domain.Gist
+	byIDs        map[domain.GistID]*domain.Gist
+	raw          map[string]string
+	validators   domain.CacheValidators
+	sentValid    domain.CacheValidators
+	notModified  bool
+	updatedSince []domain.Gist
+	since        time.Time
 }
 
 func (f *fakeRepo) GetAll(context.Context) ([]domain.Gist, error) {
 	f.getAllCalled = true
 	return f.all, f.allErr
 }
-func (f *fakeRepo) GetByID(context.Context, domain.GistID) (*domain.Gist, error) {
+func (f *fakeRepo) GetUpdatedSince(_ context.Context, since time.Time) ([]domain.Gist, error) {
+	f.since = since
+	return f.updatedSince, nil
+}
+func (f *fakeRepo) GetByIDIfModified(ctx context.Context, id domain.GistID, v domain.CacheValidators) (*domain.Gist, domain.CacheValidators, error) {
+	f.sentValid = v
+	if f.notModified {
+		return nil, v, domain.ErrNotModified{}
+	}
+	g, err := f.GetByID(ctx, id)
+	return g, f.validators, err
+}
+func (f *fakeRepo) GetAllIfModified(ctx context.Context, v domain.CacheValidators) ([]domain.Gist, domain.CacheValidators, error) {
+	f.sentValid = v
+	if f.notModified {
+		return nil, v, domain.ErrNotModified{}
+	}
+	gists, err := f.GetAll(ctx)
+	return gists, f.validators, err
+}
+func (f *fakeRepo) GetByID(_ context.Context, id domain.GistID) (*domain.Gist, error) {
+	if g, ok := f.byIDs[id]; ok {
+		return g, nil
+	}
 	return f.byID, f.byIDErr
 }
 func (f *fakeRepo) Create(_ context.Context, g *domain.Gist) error {
@@ -37,7 +76,33 @@ func (f *fakeRepo) Create(_ context.Context, g *domain.Gist) error {
 	g.ID = "newgistid123"
 	return nil
 }
-func (f *fakeRepo) Update(context.Context, *domain.Gist) error { return nil }
+func (f *fakeRepo) Update(_ context.Context, g *domain.Gist) error {
+	f.updated = append(f.updated, g)
+	if f.updateErr == nil && f.updateResult != nil {
+		*g = *f.updateResult
+	}
+	return f.updateErr
+}
+func (f *fakeRepo) GetHistory(context.Context, domain.GistID) ([]domain.GistRevision, error) {
+	return f.history, nil
+}
+func (f *fakeRepo) GetRevision(_ context.Context, id domain.GistID, sha string) (*domain.Gist, error) {
+	if g, ok := f.revisions[sha]; ok {
+		return g, nil
+	}
+	return nil, domain.ErrRevisionNotFound{ID: id, Revision: sha}
+}
+func (f *fakeRepo) GetRawContent(_ context.Context, rawURL string) (string, error) {
+	content, ok := f.raw[rawURL]
+	if !ok {
+		return "", errors.New("unexpected raw URL " + rawURL)
+	}
+	return content, nil
+}
+func (f *fakeRepo) Delete(_ context.Context, id domain.GistID) error {
+	f.deleted = append(f.deleted, id)
+	return f.deleteErr
+}
 
 type fakeCache struct {
 	gists   []domain.Gist
@@ -46,15 +111,79 @@ type fakeCache struct {
 	saveErr error
 	saved   []domain.Gist
 	cleared bool
+	removed []domain.GistID
+	put     []domain.Gist
+
+	validators      domain.CacheValidators
+	savedValidators domain.CacheValidators
+	touched         bool
+
+	details       map[domain.GistID]*domain.CachedGist
+	savedDetails  []domain.Gist
+	touchedDetail []domain.GistID
+
+	syncState domain.SyncState
+	merged    []domain.Gist
+	mergedAt  time.Time
+
+	hits, misses int
+	reset        bool
+	stats        domain.CacheStats
+	entries      []domain.CacheEntry
+	prunedBefore time.Time
 }
 
 func (f *fakeCache) GetGists() ([]domain.Gist, error) { return f.gists, f.getErr }
-func (f *fakeCache) SaveGists(g []domain.Gist) error {
+func (f *fakeCache) SaveGists(g []domain.Gist, v domain.CacheValidators) error {
 	f.saved = g
+	f.savedValidators = v
 	return f.saveErr
 }
+func (f *fakeCache) GetGistDetail(id domain.GistID) (*domain.CachedGist, error) {
+	if d, ok := f.details[id]; ok {
+		return d, nil
+	}
+	return nil, os.ErrNotExist
+}
+func (f *fakeCache) SaveGistDetail(g domain.Gist, v domain.CacheValidators) error {
+	f.savedDetails = append(f.savedDetails, g)
+	f.savedValidators = v
+	return nil
+}
+func (f *fakeCache) TouchGistDetail(id domain.GistID) error {
+	f.touchedDetail = append(f.touchedDetail, id)
+	return nil
+}
+func (f *fakeCache) MergeGists(g []domain.Gist, fetchedAt time.Time) error {
+	f.merged, f.mergedAt = g, fetchedAt
+	f.gists = append(append([]domain.Gist(nil), g...), f.gists...)
+	return nil
+}
+func (f *fakeCache) GetSyncState() domain.SyncState        { return f.syncState }
+func (f *fakeCache) GetValidators() domain.CacheValidators { return f.validators }
+func (f *fakeCache) Touch() error                          { f.touched = true; return nil }
+func (f *fakeCache) PutGist(g domain.Gist) error {
+	f.put = append(f.put, g)
+	return nil
+}
+func (f *fakeCache) RemoveGist(id domain.GistID) error {
+	f.removed = append(f.removed, id)
+	return nil
+}
 func (f *fakeCache) IsStale() bool { return f.stale }
 func (f *fakeCache) Clear() error  { f.cleared = true; return nil }
+func (f *fakeCache) RecordLookups(hits, misses int) error {
+	f.hits += hits
+	f.misses += misses
+	return nil
+}
+func (f *fakeCache) ResetCounters() error                  { f.reset = true; return nil }
+func (f *fakeCache) Stats() (*domain.CacheStats, error)    { return &f.stats, nil }
+func (f *fakeCache) Entries() ([]domain.CacheEntry, error) { return f.entries, nil }
+func (f *fakeCache) Prune(cutoff time.Time) (int, error) {
+	f.prunedBefore = cutoff
+	return len(f.entries), nil
+}
 
 type fakeFS struct {
 	files map[string][]byte
@@ -79,11 +208,64 @@ func (f *fakeFS) Size(path string) (int64, error) {
 	}
 	return int64(len(b)), nil
 }
-func (f *fakeFS) WriteFile(string, []byte) error { return nil }
-func (f *fakeFS) RemoveAll(string) error         { return nil }
+func (f *fakeFS) WriteFile(path string, content []byte) error {
+	if f.files == nil {
+		f.files = map[string][]byte{}
+	}
+	f.files[path] = content
+	return nil
+}
+func (f *fakeFS) ListFiles(dir string) ([]string, error) {
+	var names []string
+	for path := range f.files {
+		if filepath.Dir(path) == dir {
+			names = append(names, filepath.Base(path))
+		}
+	}
+	return names, nil
+}
+func (f *fakeFS) RemoveAll(path string) error {
+	delete(f.files, path)
+	return nil
+}
+
+type fakeStaging struct {
+	files   []domain.StagedFile
+	cleared bool
+}
+
+func (f *fakeStaging) Load() ([]domain.StagedFile, error) { return f.files, nil }
+func (f *fakeStaging) Save(files []domain.StagedFile) error {
+	f.files = files
+	return nil
+}
+func (f *fakeStaging) Clear() error {
+	f.files = nil
+	f.cleared = true
+	return nil
+}
+
+type fakeWorkCopy struct {
+	copies map[string]*domain.WorkingCopy
+}
+
+func (f *fakeWorkCopy) Load(dir string) (*domain.WorkingCopy, error) {
+	wc, ok := f.copies[dir]
+	if !ok {
+		return nil, domain.ErrNotWorkingCopy{Dir: dir}
+	}
+	return wc, nil
+}
+func (f *fakeWorkCopy) Save(dir string, wc *domain.WorkingCopy) error {
+	if f.copies == nil {
+		f.copies = map[string]*domain.WorkingCopy{}
+	}
+	f.copies[dir] = wc
+	return nil
+}
 
 func newSvc(repo *fakeRepo, cache *fakeCache, fs *fakeFS) *GistService {
-	return NewGistService(repo, cache, fs, &domain.Config{})
+	return NewGistService(repo, cache, &fakeStaging{}, &fakeWorkCopy{}, fs, &domain.Config{})
 }
 
 // --- PublishFiles ---
@@ -115,6 +297,20 @@ func TestPublishFiles_Success(t *testing.T) {
 	}
 }
 
+func TestPublishFiles_DefaultTags(t *testing.T) {
+	repo := &fakeRepo{}
+	fs := &fakeFS{files: map[string][]byte{"a.md": []byte("hello")}}
+	config := &domain.Config{Preferences: domain.Preferences{Tags: []string{"notes", "go"}}}
+	svc := NewGistService(repo, &fakeCache{}, &fakeStaging{}, &fakeWorkCopy{}, fs, config)
+
+	if _, err := svc.PublishFiles(context.Background(), []string{"a.md"}, "Title #Go", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.created[0].Description; got != "Title #Go #notes" {
+		t.Errorf("expected the missing default tags appended, got %q", got)
+	}
+}
+
 func TestPublishFiles_FileNotFound(t *testing.T) {
 	svc := newSvc(&fakeRepo{}, &fakeCache{}, &fakeFS{files: map[string][]byte{}})
 
@@ -222,6 +418,45 @@ func TestListGists_SaveErrorNonFatal(t *testing.T) {
 	}
 }
 
+func TestListGists_StaleCacheRevalidates(t *testing.T) {
+	cached := []domain.Gist{{ID: "cached1"}}
+	etag := domain.CacheValidators{ETag: `"abc"`}
+	repo := &fakeRepo{notModified: true}
+	cache := &fakeCache{stale: true, gists: cached, validators: etag}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	got, err := svc.ListGists(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "cached1" {
+		t.Errorf("expected cached gists on 304, got %+v", got)
+	}
+	if repo.sentValid != etag {
+		t.Errorf("expected cached validators sent, got %+v", repo.sentValid)
+	}
+	if !cache.touched || cache.saved != nil {
+		t.Errorf("expected cache touched, not rewritten (touched=%v saved=%v)", cache.touched, cache.saved)
+	}
+}
+
+func TestListGists_SavesResponseValidators(t *testing.T) {
+	etag := domain.CacheValidators{ETag: `"new"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+	repo := &fakeRepo{all: []domain.Gist{{ID: "fresh1"}}, validators: etag}
+	cache := &fakeCache{stale: true, validators: domain.CacheValidators{ETag: `"orphan"`}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	if _, err := svc.ListGists(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.sentValid.Empty() {
+		t.Errorf("validators without cached gists must not be sent, got %+v", repo.sentValid)
+	}
+	if cache.savedValidators != etag {
+		t.Errorf("expected response validators saved, got %+v", cache.savedValidators)
+	}
+}
+
 // --- SyncGists ---
 
 func TestSyncGists_ClearsThenFetches(t *testing.T) {
@@ -230,14 +465,14 @@ func TestSyncGists_ClearsThenFetches(t *testing.T) {
 	cache := &fakeCache{stale: true}
 	svc := newSvc(repo, cache, &fakeFS{})
 
-	got, err := svc.SyncGists(context.Background())
+	got, err := svc.SyncGists(context.Background(), true)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if !cache.cleared {
 		t.Error("expected cache to be cleared before fetch")
 	}
-	if len(got) != 1 || string(got[0].ID) != "synced1" {
+	if !got.Full || len(got.Gists) != 1 || string(got.Gists[0].ID) != "synced1" {
 		t.Errorf("expected fetched gist, got %+v", got)
 	}
 }
@@ -267,3 +502,263 @@ func TestGetGist_Success(t *testing.T) {
 		t.Errorf("expected abc123 gist, got %+v", got)
 	}
 }
+
+func TestGetGist_FetchesTruncatedContent(t *testing.T) {
+	g := domain.NewGist("abc123", "", true)
+	g.AddFile("small.md", "complete")
+	g.Files["big.log"] = domain.GistFile{Filename: "big.log", Content: "part", RawURL: "https://raw/big.log", Truncated: true}
+	repo := &fakeRepo{byID: g, raw: map[string]string{"https://raw/big.log": "the whole file"}}
+	svc := newSvc(repo, &fakeCache{}, &fakeFS{})
+
+	got, err := svc.GetGist(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	big := got.Files["big.log"]
+	if big.Content != "the whole file" || big.Truncated {
+		t.Errorf("expected full content, got %+v", big)
+	}
+	if got.Files["small.md"].Content != "complete" {
+		t.Errorf("untruncated file changed: %+v", got.Files["small.md"])
+	}
+}
+
+func TestGetGist_FreshDetailCacheSkipsNetwork(t *testing.T) {
+	cachedGist := &domain.Gist{ID: "abc123", Description: "cached"}
+	repo := &fakeRepo{byIDErr: errors.New("network must not be used")}
+	cache := &fakeCache{details: map[domain.GistID]*domain.CachedGist{
+		"abc123": {Gist: cachedGist},
+	}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	got, err := svc.GetGist(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Description != "cached" {
+		t.Errorf("expected cached gist, got %+v", got)
+	}
+}
+
+func TestGetGist_StaleDetailRevalidates(t *testing.T) {
+	etag := domain.CacheValidators{ETag: `"g1"`}
+	cachedGist := &domain.Gist{ID: "abc123", Description: "cached"}
+	repo := &fakeRepo{notModified: true}
+	cache := &fakeCache{details: map[domain.GistID]*domain.CachedGist{
+		"abc123": {Gist: cachedGist, Validators: etag, Stale: true},
+	}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	got, err := svc.GetGist(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != cachedGist {
+		t.Errorf("expected cached gist on 304, got %+v", got)
+	}
+	if repo.sentValid != etag {
+		t.Errorf("expected cached validators sent, got %+v", repo.sentValid)
+	}
+	if len(cache.touchedDetail) != 1 || len(cache.savedDetails) != 0 {
+		t.Errorf("expected entry touched, not rewritten: %+v", cache)
+	}
+}
+
+func TestGetGist_SavesFetchedDetail(t *testing.T) {
+	etag := domain.CacheValidators{ETag: `"new"`}
+	repo := &fakeRepo{byID: &domain.Gist{ID: "abc123", Description: "fresh"}, validators: etag}
+	cache := &fakeCache{details: map[domain.GistID]*domain.CachedGist{
+		"abc123": {Gist: &domain.Gist{ID: "abc123"}, Validators: domain.CacheValidators{ETag: `"old"`}, Stale: true},
+	}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	got, err := svc.GetGist(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Description != "fresh" {
+		t.Errorf("expected fetched gist, got %+v", got)
+	}
+	if len(cache.savedDetails) != 1 || cache.savedValidators != etag {
+		t.Errorf("expected fetched gist cached with its validators, got %+v %+v", cache.savedDetails, cache.savedValidators)
+	}
+}
+
+func TestGetGist_RawContentError(t *testing.T) {
+	g := domain.NewGist("abc123", "", true)
+	g.Files["big.log"] = domain.GistFile{Filename: "big.log", RawURL: "https://raw/big.log", Truncated: true}
+	svc := newSvc(&fakeRepo{byID: g}, &fakeCache{}, &fakeFS{})
+
+	if _, err := svc.GetGist(context.Background(), "abc123"); err == nil {
+		t.Fatal("expected an error when the full content cannot be fetched")
+	}
+}
+
+// --- UpdateGist ---
+
+func TestUpdateGist_UpdatesAndRefreshesCache(t *testing.T) {
+	repo := &fakeRepo{}
+	cache := &fakeCache{}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	g := &domain.Gist{ID: "abc123", Files: map[string]domain.GistFile{"a.md": {Content: "new"}}}
+	if err := svc.UpdateGist(context.Background(), g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.updated) != 1 || repo.updated[0] != g {
+		t.Fatalf("expected one update with the given gist, got %v", repo.updated)
+	}
+	if len(cache.put) != 1 || cache.put[0].ID != "abc123" {
+		t.Errorf("expected cache refreshed with abc123, got %+v", cache.put)
+	}
+}
+
+func TestUpdateGist_Error(t *testing.T) {
+	repo := &fakeRepo{updateErr: errors.New("conflict")}
+	cache := &fakeCache{}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	err := svc.UpdateGist(context.Background(), &domain.Gist{ID: "abc123"})
+	if err == nil || !strings.Contains(err.Error(), "update gist") {
+		t.Fatalf("expected 'update gist' wrapping error, got %v", err)
+	}
+	if len(cache.put) != 0 {
+		t.Errorf("cache must not be touched when update fails, got %+v", cache.put)
+	}
+}
+
+func TestUpdateGist_InvalidID(t *testing.T) {
+	svc := newSvc(&fakeRepo{}, &fakeCache{}, &fakeFS{})
+
+	err := svc.UpdateGist(context.Background(), &domain.Gist{})
+	var invalid domain.ErrInvalidGistID
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected domain.ErrInvalidGistID, got %v", err)
+	}
+}
+
+// --- DeleteGist ---
+
+func TestDeleteGist_DeletesAndEvictsCache(t *testing.T) {
+	repo := &fakeRepo{}
+	cache := &fakeCache{}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	if err := svc.DeleteGist(context.Background(), "abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "abc123" {
+		t.Errorf("expected abc123 deleted, got %v", repo.deleted)
+	}
+	if len(cache.removed) != 1 || cache.removed[0] != "abc123" {
+		t.Errorf("expected abc123 evicted from cache, got %v", cache.removed)
+	}
+}
+
+func TestDeleteGist_ErrorKeepsCache(t *testing.T) {
+	repo := &fakeRepo{deleteErr: errors.New("forbidden")}
+	cache := &fakeCache{}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	err := svc.DeleteGist(context.Background(), "abc123")
+	if err == nil || !strings.Contains(err.Error(), "delete gist") {
+		t.Fatalf("expected 'delete gist' wrapping error, got %v", err)
+	}
+	if len(cache.removed) != 0 {
+		t.Errorf("cache must not be touched when delete fails, got %v", cache.removed)
+	}
+}
+
+func TestDeleteGist_InvalidID(t *testing.T) {
+	svc := newSvc(&fakeRepo{}, &fakeCache{}, &fakeFS{})
+
+	err := svc.DeleteGist(context.Background(), "")
+	var invalid domain.ErrInvalidGistID
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected domain.ErrInvalidGistID, got %v", err)
+	}
+}
+
+// --- PrepareUpdate ---
+
+func TestPrepareUpdate_ClassifiesFiles(t *testing.T) {
+	current := &domain.Gist{
+		ID:          "abc123",
+		Description: "Old title #go #blog",
+		Files: map[string]domain.GistFile{
+			"post.md": {Filename: "post.md", Content: "a\nb\n"},
+			"same.md": {Filename: "same.md", Content: "same\n"},
+		},
+	}
+	fs := &fakeFS{files: map[string][]byte{
+		"dir/post.md": []byte("a\nc\nd\n"),
+		"dir/same.md": []byte("same\n"),
+		"dir/new.md":  []byte("new\n"),
+	}}
+	svc := newSvc(&fakeRepo{byID: current}, &fakeCache{}, fs)
+
+	update, changes, err := svc.PrepareUpdate(context.Background(), "abc123",
+		[]string{"dir/post.md", "dir/same.md", "dir/new.md"}, "New title #Go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if update.Description != "New title #Go #blog" {
+		t.Errorf("expected hashtags preserved, got %q", update.Description)
+	}
+	if len(update.Files) != 2 {
+		t.Errorf("expected only changed files in update, got %v", update.Files)
+	}
+	if _, ok := update.Files["same.md"]; ok {
+		t.Error("unchanged file must not be sent")
+	}
+
+	want := []domain.FileChange{
+		{Filename: "post.md", Status: domain.FileModified, LinesAdded: 2, LinesRemoved: 1},
+		{Filename: "same.md", Status: domain.FileUnchanged},
+		{Filename: "new.md", Status: domain.FileAdded, LinesAdded: 1},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestPrepareUpdate_KeepsDescriptionWhenEmpty(t *testing.T) {
+	current := &domain.Gist{ID: "abc123", Description: "Title #tag", Files: map[string]domain.GistFile{}}
+	fs := &fakeFS{files: map[string][]byte{"a.md": []byte("x")}}
+	svc := newSvc(&fakeRepo{byID: current}, &fakeCache{}, fs)
+
+	update, _, err := svc.PrepareUpdate(context.Background(), "abc123", []string{"a.md"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update.Description != "Title #tag" {
+		t.Errorf("expected description unchanged, got %q", update.Description)
+	}
+}
+
+func TestPrepareUpdate_DuplicateBaseNames(t *testing.T) {
+	current := &domain.Gist{ID: "abc123", Files: map[string]domain.GistFile{}}
+	fs := &fakeFS{files: map[string][]byte{"a/x.md": []byte("1"), "b/x.md": []byte("2")}}
+	svc := newSvc(&fakeRepo{byID: current}, &fakeCache{}, fs)
+
+	_, _, err := svc.PrepareUpdate(context.Background(), "abc123", []string{"a/x.md", "b/x.md"}, "")
+	if err == nil || !strings.Contains(err.Error(), "both map to") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestPrepareUpdate_FileNotFound(t *testing.T) {
+	svc := newSvc(&fakeRepo{}, &fakeCache{}, &fakeFS{files: map[string][]byte{}})
+
+	_, _, err := svc.PrepareUpdate(context.Background(), "abc123", []string{"missing.md"}, "")
+	var nf domain.ErrFileNotFound
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected domain.ErrFileNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gist/internal/diff"
+	"gist/internal/domain"
+)
+
+// GistHistory retrieves the revisions of a gist, newest first
+func (s *GistService) GistHistory(ctx context.Context, id string) ([]domain.GistRevision, error) {
+	gistID := domain.GistID(id)
+	if !gistID.Valid() {
+		return nil, domain.ErrInvalidGistID{ID: id}
+	}
+
+	history, err := s.gistRepo.GetHistory(ctx, gistID)
+	if err != nil {
+		return nil, fmt.Errorf("get history of %s: %w", id, err)
+	}
+
+	return history, nil
+}
+
+// GetGistRevision retrieves a gist as it was at a revision. The revision may
+// be a full SHA or a unique prefix of one.
+func (s *GistService) GetGistRevision(ctx context.Context, id, revision string) (*domain.Gist, error) {
+	history, err := s.GistHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := resolveRevision(domain.GistID(id), history, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getRevision(ctx, domain.GistID(id), version)
+}
+
+// DiffRevisions returns a gist at two revisions for comparison. An empty to
+// means the latest revision; an empty from means the revision before to.
+func (s *GistService) DiffRevisions(ctx context.Context, id, from, to string) (*domain.Gist, *domain.Gist, error) {
+	history, err := s.GistHistory(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history) == 0 {
+		return nil, nil, fmt.Errorf("gist %s has no revisions", id)
+	}
+	gistID := domain.GistID(id)
+
+	toVersion := history[0].Version
+	if to != "" {
+		if toVersion, err = resolveRevision(gistID, history, to); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var fromVersion string
+	if from != "" {
+		if fromVersion, err = resolveRevision(gistID, history, from); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		for i, rev := range history {
+			if rev.Version == toVersion {
+				if i+1 < len(history) {
+					fromVersion = history[i+1].Version
+				}
+				break
+			}
+		}
+	}
+
+	newGist, err := s.getRevision(ctx, gistID, toVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The first revision is compared against an empty gist
+	oldGist := &domain.Gist{ID: gistID, Files: map[string]domain.GistFile{}}
+	if fromVersion != "" {
+		if oldGist, err = s.getRevision(ctx, gistID, fromVersion); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return oldGist, newGist, nil
+}
+
+// PrepareRestore builds the update that makes the current files of a gist
+// match an earlier revision, along with a per-file summary. Files that did
+// not exist at that revision are deleted; the description is left as is.
+func (s *GistService) PrepareRestore(ctx context.Context, id, revision string) (*domain.Gist, []domain.FileChange, error) {
+	target, err := s.GetGistRevision(ctx, id, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := fetchFullGist(ctx, s.gistRepo, domain.GistID(id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get gist %s: %w", id, err)
+	}
+
+	update := &domain.Gist{
+		ID:          current.ID,
+		Description: current.Description,
+		Public:      current.Public,
+		Files:       make(map[string]domain.GistFile),
+	}
+
+	var changes []domain.FileChange
+	for name, file := range target.Files {
+		old, exists := current.Files[name]
+		change := domain.FileChange{Filename: name, Status: domain.FileAdded}
+		if exists {
+			change.Status = domain.FileModified
+		}
+		change.LinesAdded, change.LinesRemoved = diff.Stat(diff.Lines(old.Content, file.Content))
+
+		if exists && old.Content == file.Content {
+			change.Status = domain.FileUnchanged
+		} else {
+			update.AddFile(name, file.Content)
+		}
+		changes = append(changes, change)
+	}
+
+	for name, file := range current.Files {
+		if _, kept := target.Files[name]; kept {
+			continue
+		}
+		_, removed := diff.Stat(diff.Lines(file.Content, ""))
+		changes = append(changes, domain.FileChange{Filename: name, Status: domain.FileDeleted, LinesRemoved: removed})
+		update.RemoveFile(name)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Filename < changes[j].Filename
+	})
+
+	return update, changes, nil
+}
+
+// getRevision fetches a single revision with a wrapped error
+func (s *GistService) getRevision(ctx context.Context, id domain.GistID, version string) (*domain.Gist, error) {
+	gist, err := s.gistRepo.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("get revision %s: %w", shortRevision(version), err)
+	}
+	if err := completeTruncatedFiles(ctx, s.gistRepo, gist); err != nil {
+		return nil, fmt.Errorf("get revision %s: %w", shortRevision(version), err)
+	}
+	return gist, nil
+}
+
+// resolveRevision expands a revision prefix to a full version SHA
+func resolveRevision(id domain.GistID, history []domain.GistRevision, revision string) (string, error) {
+	var matches []string
+	for _, rev := range history {
+		if rev.Version == revision {
+			return rev.Version, nil
+		}
+		if strings.HasPrefix(rev.Version, revision) {
+			matches = append(matches, rev.Version)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", domain.ErrRevisionNotFound{ID: id, Revision: revision}
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous revision %q matches %d revisions", revision, len(matches))
+	}
+}
+
+// shortRevision abbreviates a revision SHA for messages
+func shortRevision(version string) string {
+	if len(version) > 8 {
+		return version[:8]
+	}
+	return version
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func historyRepo() *fakeRepo {
+	return &fakeRepo{
+		history: []domain.GistRevision{
+			{Version: "cccc3333"},
+			{Version: "bbbb2222"},
+			{Version: "bbbb1111"},
+		},
+		revisions: map[string]*domain.Gist{
+			"cccc3333": {ID: "g", Description: "v3"},
+			"bbbb2222": {ID: "g", Description: "v2"},
+			"bbbb1111": {ID: "g", Description: "v1"},
+		},
+	}
+}
+
+func TestGetGistRevision_Prefix(t *testing.T) {
+	svc := newSvc(historyRepo(), &fakeCache{}, &fakeFS{})
+
+	g, err := svc.GetGistRevision(context.Background(), "g", "bbbb2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Description != "v2" {
+		t.Errorf("expected v2, got %q", g.Description)
+	}
+}
+
+func TestGetGistRevision_AmbiguousAndUnknown(t *testing.T) {
+	svc := newSvc(historyRepo(), &fakeCache{}, &fakeFS{})
+
+	_, err := svc.GetGistRevision(context.Background(), "g", "bbbb")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguous revision error, got %v", err)
+	}
+
+	_, err = svc.GetGistRevision(context.Background(), "g", "ffff")
+	var nf domain.ErrRevisionNotFound
+	if !errors.As(err, &nf) {
+		t.Errorf("expected domain.ErrRevisionNotFound, got %v", err)
+	}
+}
+
+func TestDiffRevisions_Defaults(t *testing.T) {
+	svc := newSvc(historyRepo(), &fakeCache{}, &fakeFS{})
+
+	tests := []struct {
+		from, to         string
+		wantOld, wantNew string
+	}{
+		{"", "", "v2", "v3"},
+		{"bbbb1", "", "v1", "v3"},
+		{"bbbb1", "bbbb2", "v1", "v2"},
+		{"", "bbbb1", "", "v1"}, // first revision compares against an empty gist
+	}
+	for _, tt := range tests {
+		oldGist, newGist, err := svc.DiffRevisions(context.Background(), "g", tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("DiffRevisions(%q, %q): %v", tt.from, tt.to, err)
+		}
+		if oldGist.Description != tt.wantOld || newGist.Description != tt.wantNew {
+			t.Errorf("DiffRevisions(%q, %q) = %q..%q, want %q..%q",
+				tt.from, tt.to, oldGist.Description, newGist.Description, tt.wantOld, tt.wantNew)
+		}
+	}
+}
+
+func TestPrepareRestore(t *testing.T) {
+	repo := historyRepo()
+	repo.revisions["bbbb1111"] = &domain.Gist{ID: "g", Files: map[string]domain.GistFile{
+		"post.md": {Content: "old\n"},
+		"same.md": {Content: "same\n"},
+		"gone.md": {Content: "was here\n"},
+	}}
+	repo.byID = &domain.Gist{ID: "g", Description: "current", Files: map[string]domain.GistFile{
+		"post.md":  {Content: "new\n"},
+		"same.md":  {Content: "same\n"},
+		"extra.md": {Content: "a\nb\n"},
+	}}
+	svc := newSvc(repo, &fakeCache{}, &fakeFS{})
+
+	update, changes, err := svc.PrepareRestore(context.Background(), "g", "bbbb1")
+	if err != nil {
+		t.Fatalf("PrepareRestore: %v", err)
+	}
+
+	if update.Description != "current" {
+		t.Errorf("description must not change, got %q", update.Description)
+	}
+	if got := update.Files["post.md"]; got.Content != "old\n" || got.Deleted {
+		t.Errorf("expected post.md restored, got %+v", got)
+	}
+	if got := update.Files["gone.md"]; got.Content != "was here\n" {
+		t.Errorf("expected gone.md re-added, got %+v", got)
+	}
+	if got := update.Files["extra.md"]; !got.Deleted {
+		t.Errorf("expected extra.md deleted, got %+v", got)
+	}
+	if _, ok := update.Files["same.md"]; ok {
+		t.Error("unchanged file must not be sent")
+	}
+
+	want := []domain.FileChange{
+		{Filename: "extra.md", Status: domain.FileDeleted, LinesRemoved: 2},
+		{Filename: "gone.md", Status: domain.FileAdded, LinesAdded: 1},
+		{Filename: "post.md", Status: domain.FileModified, LinesAdded: 1, LinesRemoved: 1},
+		{Filename: "same.md", Status: domain.FileUnchanged},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
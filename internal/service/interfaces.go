@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"gist/internal/domain"
 )
@@ -11,14 +12,53 @@ type GistRepository interface {
 	// GetAll retrieves all gists for the authenticated user
 	GetAll(ctx context.Context) ([]domain.Gist, error)
 
+	// GetAllIfModified retrieves all gists unless the listing still matches
+	// validators, in which case it returns domain.ErrNotModified
+	GetAllIfModified(ctx context.Context, validators domain.CacheValidators) ([]domain.Gist, domain.CacheValidators, error)
+
+	// GetUpdatedSince retrieves the gists updated at or after since
+	GetUpdatedSince(ctx context.Context, since time.Time) ([]domain.Gist, error)
+
 	// GetByID retrieves a specific gist by ID
 	GetByID(ctx context.Context, id domain.GistID) (*domain.Gist, error)
 
+	// GetByIDIfModified retrieves a gist unless it still matches validators,
+	// in which case it returns domain.ErrNotModified
+	GetByIDIfModified(ctx context.Context, id domain.GistID, validators domain.CacheValidators) (*domain.Gist, domain.CacheValidators, error)
+
 	// Create creates a new gist
 	Create(ctx context.Context, gist *domain.Gist) error
 
 	// Update updates an existing gist
 	Update(ctx context.Context, gist *domain.Gist) error
+
+	// Delete removes a gist
+	Delete(ctx context.Context, id domain.GistID) error
+
+	// GetHistory retrieves the revisions of a gist, newest first
+	GetHistory(ctx context.Context, id domain.GistID) ([]domain.GistRevision, error)
+
+	// GetRevision retrieves a gist as it was at a specific revision
+	GetRevision(ctx context.Context, id domain.GistID, sha string) (*domain.Gist, error)
+
+	// GetRawContent downloads a file body from its raw URL
+	GetRawContent(ctx context.Context, rawURL string) (string, error)
+}
+
+// AuthRepository defines the contract for inspecting the configured token
+type AuthRepository interface {
+	// GetAuthStatus retrieves the account, scopes and rate limit of the token
+	GetAuthStatus(ctx context.Context) (*domain.AuthStatus, error)
+}
+
+// DeviceAuthRepository defines the contract for the OAuth device flow
+type DeviceAuthRepository interface {
+	// RequestDeviceCode starts an authorization for scopes
+	RequestDeviceCode(ctx context.Context, scopes []string) (*domain.DeviceCode, error)
+
+	// PollDeviceToken waits for the user to authorize code and returns the
+	// access token
+	PollDeviceToken(ctx context.Context, code *domain.DeviceCode) (string, error)
 }
 
 // CacheRepository defines the contract for local caching operations
@@ -26,14 +66,105 @@ type CacheRepository interface {
 	// GetGists retrieves cached gists
 	GetGists() ([]domain.Gist, error)
 
-	// SaveGists caches gists locally
-	SaveGists(gists []domain.Gist) error
+	// SaveGists caches gists locally with the validators of the response
+	// they came from
+	SaveGists(gists []domain.Gist, validators domain.CacheValidators) error
+
+	// GetValidators returns the validators of the cached gists, if any
+	GetValidators() domain.CacheValidators
+
+	// Touch marks the cached gists as freshly fetched
+	Touch() error
+
+	// MergeGists adds or replaces gists fetched incrementally at fetchedAt
+	MergeGists(gists []domain.Gist, fetchedAt time.Time) error
+
+	// GetSyncState reports when the cached gists were last synced
+	GetSyncState() domain.SyncState
+
+	// PutGist adds or replaces a single gist in the cache
+	PutGist(gist domain.Gist) error
+
+	// RemoveGist drops a single gist from the cache
+	RemoveGist(id domain.GistID) error
+
+	// GetGistDetail retrieves a gist from the detail cache
+	GetGistDetail(id domain.GistID) (*domain.CachedGist, error)
+
+	// SaveGistDetail caches a fully fetched gist with the validators of the
+	// response it came from
+	SaveGistDetail(gist domain.Gist, validators domain.CacheValidators) error
+
+	// TouchGistDetail marks a cached gist as freshly fetched
+	TouchGistDetail(id domain.GistID) error
 
 	// IsStale checks if cache needs refreshing
 	IsStale() bool
 
 	// Clear removes all cached data
 	Clear() error
+
+	// RecordLookups adds to the counts of gist lookups that were cache hits
+	// and misses
+	RecordLookups(hits, misses int) error
+
+	// ResetCounters restarts the lookup counters
+	ResetCounters() error
+
+	// Stats summarizes the cache contents and lookup counters
+	Stats() (*domain.CacheStats, error)
+
+	// Entries lists the cached gists, listed ones and fully fetched ones
+	Entries() ([]domain.CacheEntry, error)
+
+	// Prune removes entries fetched before cutoff and returns how many
+	Prune(cutoff time.Time) (int, error)
+}
+
+// StagingRepository defines the contract for the local staging area
+type StagingRepository interface {
+	// Load retrieves the staged files
+	Load() ([]domain.StagedFile, error)
+
+	// Save persists the staged files
+	Save(files []domain.StagedFile) error
+
+	// Clear removes all staged files
+	Clear() error
+}
+
+// WorkingCopyRepository defines the contract for working copy metadata
+type WorkingCopyRepository interface {
+	// Load reads the metadata of the working copy in dir
+	Load(dir string) (*domain.WorkingCopy, error)
+
+	// Save writes the metadata of the working copy in dir
+	Save(dir string, wc *domain.WorkingCopy) error
+}
+
+// ArchiveRepository defines the contract for backup archive storage
+type ArchiveRepository interface {
+	// Create starts a new archive at location
+	Create(location string) (ArchiveWriter, error)
+
+	// Open reads an existing archive at location
+	Open(location string) (ArchiveReader, error)
+}
+
+// ArchiveWriter receives the files of an archive, addressed by
+// slash-separated paths relative to the archive root
+type ArchiveWriter interface {
+	// WriteFile adds a file to the archive
+	WriteFile(path string, content []byte) error
+
+	// Close finalizes the archive
+	Close() error
+}
+
+// ArchiveReader provides the files of an archive
+type ArchiveReader interface {
+	// ReadFile returns the content of a file in the archive
+	ReadFile(path string) ([]byte, error)
 }
 
 // FileSystem defines the contract for file system operations
@@ -50,18 +181,56 @@ type FileSystem interface {
 	// WriteFile writes content to a file
 	WriteFile(path string, content []byte) error
 
+	// ListFiles returns the names of the regular files in a directory
+	ListFiles(dir string) ([]string, error)
+
 	// RemoveAll removes a directory and all contents
 	RemoveAll(path string) error
 }
 
+// SecretStore defines the contract for keeping GitHub tokens outside the
+// config file
+type SecretStore interface {
+	// Get returns the token of user on host, or domain.ErrSecretNotFound
+	Get(host, user string) (string, error)
+
+	// Set stores the token of user on host
+	Set(host, user, token string) error
+
+	// Delete removes the token of user on host, if any
+	Delete(host, user string) error
+}
+
+// SettingsRepository defines the contract for the layered settings. The
+// layers are domain.SourceUser, domain.SourceProject and domain.SourceEnv.
+type SettingsRepository interface {
+	// Read returns the keys a layer sets, by dotted name
+	Read(source string) (map[string]string, error)
+
+	// Set writes a key to the file of a layer
+	Set(source, key, value string) error
+
+	// Path returns the file of a layer
+	Path(source string) (string, error)
+}
+
 // ConfigRepository defines the contract for configuration operations
 type ConfigRepository interface {
-	// Load retrieves configuration
+	// Load retrieves the configuration of the selected profile
 	Load() (*domain.Config, error)
 
-	// Save persists configuration
+	// Save persists configuration as the profile it names
 	Save(config *domain.Config) error
 
 	// GetFromEnv loads config from environment variables
 	GetFromEnv() (*domain.Config, error)
+
+	// ListProfiles returns the configured profiles
+	ListProfiles() ([]domain.Profile, error)
+
+	// UseProfile makes a profile the current one
+	UseProfile(name string) error
+
+	// RemoveProfile deletes a profile
+	RemoveProfile(name string) error
 }
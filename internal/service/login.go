@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gist/internal/domain"
+)
+
+// LoginService signs in to GitHub through the OAuth device flow and saves
+// the resulting credentials
+type LoginService struct {
+	deviceRepo func(apiURL string) DeviceAuthRepository
+	authRepo   func(apiURL, token string) AuthRepository
+	configRepo ConfigRepository
+}
+
+// NewLoginService creates a new login service with injected dependencies.
+// deviceRepo returns the device flow of the GitHub instance serving an API
+// URL; authRepo returns a repository for that API authenticated with a
+// token, used to look up the account a new token belongs to.
+func NewLoginService(deviceRepo func(apiURL string) DeviceAuthRepository, authRepo func(apiURL, token string) AuthRepository, configRepo ConfigRepository) *LoginService {
+	return &LoginService{
+		deviceRepo: deviceRepo,
+		authRepo:   authRepo,
+		configRepo: configRepo,
+	}
+}
+
+// DeviceLogin runs the device flow: it requests a code, hands it to prompt
+// to show the user, waits for the authorization and saves the token with
+// the username GitHub reports for it. config names the profile, token store
+// and API URL to save into; its user and token are filled in.
+func (s *LoginService) DeviceLogin(ctx context.Context, config *domain.Config, prompt func(code *domain.DeviceCode)) (*domain.AuthStatus, error) {
+	if err := domain.CheckTokenStore(config.TokenStore, config.CredentialHelper); err != nil {
+		return nil, err
+	}
+	apiURL, err := domain.NormalizeAPIURL(config.APIURL)
+	if err != nil {
+		return nil, err
+	}
+	config.APIURL = apiURL
+	deviceRepo := s.deviceRepo(apiURL)
+
+	code, err := deviceRepo.RequestDeviceCode(ctx, []string{domain.GistScope})
+	if err != nil {
+		return nil, fmt.Errorf("request device code: %w", err)
+	}
+	prompt(code)
+
+	token, err := deviceRepo.PollDeviceToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("wait for authorization: %w", err)
+	}
+
+	status, err := s.authRepo(apiURL, token).GetAuthStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("look up user: %w", err)
+	}
+	if status.ScopesKnown && !status.HasScope(domain.GistScope) {
+		return status, domain.ErrMissingScope{Scope: domain.GistScope}
+	}
+
+	config.GitHubUser = status.Login
+	config.GitHubToken = token
+	if err := s.configRepo.Save(config); err != nil {
+		return status, fmt.Errorf("save configuration: %w", err)
+	}
+
+	return status, nil
+}
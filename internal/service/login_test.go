@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+type fakeDeviceRepo struct {
+	apiURL string
+	scopes []string
+	token  string
+	err    error
+}
+
+func (f *fakeDeviceRepo) RequestDeviceCode(ctx context.Context, scopes []string) (*domain.DeviceCode, error) {
+	f.scopes = scopes
+	return &domain.DeviceCode{DeviceCode: "dev", UserCode: "ABCD-1234"}, nil
+}
+
+func (f *fakeDeviceRepo) PollDeviceToken(ctx context.Context, code *domain.DeviceCode) (string, error) {
+	return f.token, f.err
+}
+
+func newTestLoginService(device *fakeDeviceRepo, status *domain.AuthStatus, configRepo *fakeConfigRepo) (*LoginService, *string) {
+	var usedToken string
+	deviceRepo := func(apiURL string) DeviceAuthRepository {
+		device.apiURL = apiURL
+		return device
+	}
+	authRepo := func(apiURL, token string) AuthRepository {
+		usedToken = apiURL + " " + token
+		return &fakeAuthRepo{status: status}
+	}
+	return NewLoginService(deviceRepo, authRepo, configRepo), &usedToken
+}
+
+func TestLoginService_DeviceLogin(t *testing.T) {
+	device := &fakeDeviceRepo{token: "oauth-tok"}
+	configRepo := &fakeConfigRepo{}
+	svc, usedToken := newTestLoginService(device,
+		&domain.AuthStatus{Login: "octocat", Scopes: []string{"gist"}, ScopesKnown: true}, configRepo)
+
+	config := domain.NewConfig("", "")
+	config.Profile = "team"
+	config.APIURL = "https://github.example.com/"
+	var shown string
+	status, err := svc.DeviceLogin(context.Background(), config, func(code *domain.DeviceCode) { shown = code.UserCode })
+	if err != nil {
+		t.Fatalf("DeviceLogin: %v", err)
+	}
+
+	if len(device.scopes) != 1 || device.scopes[0] != domain.GistScope {
+		t.Errorf("expected the gist scope requested, got %v", device.scopes)
+	}
+	if shown != "ABCD-1234" {
+		t.Errorf("expected the user code shown, got %q", shown)
+	}
+	if device.apiURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected the device flow of the normalized API URL, got %q", device.apiURL)
+	}
+	if *usedToken != "https://github.example.com/api/v3 oauth-tok" || status.Login != "octocat" {
+		t.Errorf("expected the user looked up with the new token, got %q status %+v", *usedToken, status)
+	}
+	saved := configRepo.saved
+	if saved == nil || saved.Profile != "team" || saved.GitHubUser != "octocat" || saved.GitHubToken != "oauth-tok" ||
+		saved.APIURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected the discovered credentials saved to the team profile, got %+v", saved)
+	}
+}
+
+func TestLoginService_DeviceLoginFailures(t *testing.T) {
+	denied := domain.ErrDeviceFlow{Code: "access_denied"}
+	configRepo := &fakeConfigRepo{}
+
+	svc, _ := newTestLoginService(&fakeDeviceRepo{err: denied}, nil, configRepo)
+	var flowErr domain.ErrDeviceFlow
+	if _, err := svc.DeviceLogin(context.Background(), domain.NewConfig("", ""), func(*domain.DeviceCode) {}); !errors.As(err, &flowErr) {
+		t.Errorf("expected domain.ErrDeviceFlow, got %v", err)
+	}
+
+	svc, _ = newTestLoginService(&fakeDeviceRepo{token: "tok"},
+		&domain.AuthStatus{Login: "octocat", ScopesKnown: true}, configRepo)
+	var missing domain.ErrMissingScope
+	if _, err := svc.DeviceLogin(context.Background(), domain.NewConfig("", ""), func(*domain.DeviceCode) {}); !errors.As(err, &missing) {
+		t.Errorf("expected domain.ErrMissingScope, got %v", err)
+	}
+
+	if configRepo.saved != nil {
+		t.Errorf("expected nothing saved after a failed login, got %+v", configRepo.saved)
+	}
+}
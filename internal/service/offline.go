@@ -0,0 +1,33 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// unreachable reports whether err means GitHub could not be reached, so
+// cached data is the best answer available: a network failure, offline
+// mode, or a server error that persisted through retries.
+func unreachable(err error) bool {
+	var network domain.ErrNetwork
+	var offline domain.ErrOffline
+	var apiErr domain.ErrAPIRequest
+	return errors.As(err, &network) ||
+		errors.As(err, &offline) ||
+		(errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError)
+}
+
+// warnStale tells the user on stderr that cached data is being served
+// because of err
+func warnStale(err error, what string, fetchedAt time.Time) {
+	since := "an unknown time"
+	if !fetchedAt.IsZero() {
+		since = fetchedAt.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v; showing cached %s, stale since %s\n", err, what, since)
+}
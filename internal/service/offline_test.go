@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+func TestListGists_UnreachableServesStaleCache(t *testing.T) {
+	for name, fetchErr := range map[string]error{
+		"network": domain.ErrNetwork{Err: errors.New("dial tcp: no route to host")},
+		"offline": domain.ErrOffline{},
+		"5xx":     domain.ErrAPIRequest{StatusCode: http.StatusBadGateway},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeRepo{allErr: fetchErr}
+			cache := &fakeCache{
+				stale:     true,
+				gists:     []domain.Gist{{ID: "cached1"}},
+				syncState: domain.SyncState{FetchedAt: time.Now().Add(-time.Hour)},
+			}
+			svc := newSvc(repo, cache, &fakeFS{})
+
+			got, err := svc.ListGists(context.Background())
+			if err != nil {
+				t.Fatalf("expected stale cache, got error %v", err)
+			}
+			if len(got) != 1 || got[0].ID != "cached1" {
+				t.Errorf("expected cached gists, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestListGists_OtherErrorsNotMasked(t *testing.T) {
+	repo := &fakeRepo{allErr: domain.ErrAPIRequest{StatusCode: http.StatusUnauthorized}}
+	cache := &fakeCache{stale: true, gists: []domain.Gist{{ID: "cached1"}}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	if _, err := svc.ListGists(context.Background()); err == nil {
+		t.Fatal("expected an authentication error to be returned, not the cache")
+	}
+}
+
+func TestListGists_UnreachableWithoutCache(t *testing.T) {
+	for name, cache := range map[string]*fakeCache{
+		"missing": {stale: true, getErr: errors.New("no cache")},
+		"empty":   {stale: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := newSvc(&fakeRepo{allErr: domain.ErrOffline{}}, cache, &fakeFS{})
+
+			got, err := svc.ListGists(context.Background())
+			var offline domain.ErrOffline
+			if !errors.As(err, &offline) {
+				t.Fatalf("expected domain.ErrOffline, got %v (%d gists)", err, len(got))
+			}
+		})
+	}
+}
+
+func TestGetGist_UnreachableServesStaleDetail(t *testing.T) {
+	cachedGist := &domain.Gist{ID: "abc123", Description: "cached"}
+	repo := &fakeRepo{byIDErr: domain.ErrNetwork{Err: errors.New("timeout")}}
+	cache := &fakeCache{details: map[domain.GistID]*domain.CachedGist{
+		"abc123": {Gist: cachedGist, Stale: true, FetchedAt: time.Now().Add(-time.Hour)},
+	}}
+	svc := newSvc(repo, cache, &fakeFS{})
+
+	got, err := svc.GetGist(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("expected stale detail, got error %v", err)
+	}
+	if got != cachedGist {
+		t.Errorf("expected cached gist, got %+v", got)
+	}
+}
+
+func TestGetGist_UnreachableWithoutDetail(t *testing.T) {
+	repo := &fakeRepo{byIDErr: domain.ErrOffline{}}
+	svc := newSvc(repo, &fakeCache{}, &fakeFS{})
+
+	_, err := svc.GetGist(context.Background(), "abc123")
+	var offline domain.ErrOffline
+	if !errors.As(err, &offline) {
+		t.Fatalf("expected domain.ErrOffline, got %v", err)
+	}
+}
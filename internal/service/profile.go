@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+
+	"gist/internal/domain"
+)
+
+// ProfileService manages the named profiles of the config file
+type ProfileService struct {
+	configRepo ConfigRepository
+	fs         FileSystem
+	cacheBase  string
+}
+
+// NewProfileService creates a new profile service with injected
+// dependencies. cacheBase is the directory holding the profile caches.
+func NewProfileService(configRepo ConfigRepository, fs FileSystem, cacheBase string) *ProfileService {
+	return &ProfileService{
+		configRepo: configRepo,
+		fs:         fs,
+		cacheBase:  cacheBase,
+	}
+}
+
+// ListProfiles returns the configured profiles
+func (s *ProfileService) ListProfiles() ([]domain.Profile, error) {
+	profiles, err := s.configRepo.ListProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// UseProfile makes a profile the one used when none is selected
+func (s *ProfileService) UseProfile(name string) error {
+	return s.configRepo.UseProfile(name)
+}
+
+// RemoveProfile deletes a profile along with its cache. The default
+// profile's cache directory also holds the other profiles' caches, so it is
+// left in place.
+func (s *ProfileService) RemoveProfile(name string) error {
+	if err := s.configRepo.RemoveProfile(name); err != nil {
+		return err
+	}
+
+	if name == domain.DefaultProfile {
+		return nil
+	}
+	if err := s.fs.RemoveAll(domain.ProfileCacheDir(s.cacheBase, name)); err != nil {
+		fmt.Printf("Warning: failed to remove cache of profile %s: %v\n", name, err)
+	}
+	return nil
+}
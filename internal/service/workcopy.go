@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gist/internal/diff"
+	"gist/internal/domain"
+)
+
+// CloneGist writes every file of a gist into dir along with working copy
+// metadata. It refuses to overwrite an existing working copy or files.
+func (s *GistService) CloneGist(ctx context.Context, id, dir string) (*domain.WorkingCopy, error) {
+	gistID := domain.GistID(id)
+	if !gistID.Valid() {
+		return nil, domain.ErrInvalidGistID{ID: id}
+	}
+
+	if s.fs.Exists(filepath.Join(dir, domain.WorkingCopyFile)) {
+		return nil, fmt.Errorf("%s is already a gist working copy", dir)
+	}
+
+	gist, err := fetchFullGist(ctx, s.gistRepo, gistID)
+	if err != nil {
+		return nil, fmt.Errorf("get gist %s: %w", id, err)
+	}
+
+	for name := range gist.Files {
+		if err := checkWorkingName(name); err != nil {
+			return nil, err
+		}
+		if path := workingPath(dir, name); s.fs.Exists(path) {
+			return nil, fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	for name, file := range gist.Files {
+		if err := s.fs.WriteFile(workingPath(dir, name), []byte(file.Content)); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	wc := domain.NewWorkingCopy(gist)
+	if err := s.workCopy.Save(dir, wc); err != nil {
+		return nil, fmt.Errorf("save working copy: %w", err)
+	}
+
+	return wc, nil
+}
+
+// PullGist updates the working copy in dir to the latest gist version. Files
+// edited locally since the last clone or pull are never overwritten or
+// deleted unless force is set; in that case nothing is written and
+// domain.ErrLocalChanges lists them.
+func (s *GistService) PullGist(ctx context.Context, dir string, force bool) ([]domain.FileChange, error) {
+	wc, err := s.workCopy.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := fetchFullGist(ctx, s.gistRepo, wc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get gist %s: %w", wc.ID, err)
+	}
+
+	var changes []domain.FileChange
+	var conflicts []string
+	writes := make(map[string]string)
+	var deletes []string
+
+	for name, file := range remote.Files {
+		if err := checkWorkingName(name); err != nil {
+			return nil, err
+		}
+		remoteHash := domain.HashContent([]byte(file.Content))
+		if wc.Files[name] == remoteHash {
+			continue // unchanged remotely
+		}
+
+		local, exists, modified := s.localState(dir, name, wc.Files[name])
+		if exists && local == file.Content {
+			// Already matches remotely; just record it as synced
+			continue
+		}
+		if modified && !force {
+			conflicts = append(conflicts, name)
+			continue
+		}
+
+		change := domain.FileChange{Filename: name, Status: domain.FileAdded}
+		if exists {
+			change.Status = domain.FileModified
+		}
+		change.LinesAdded, change.LinesRemoved = diff.Stat(diff.Lines(local, file.Content))
+		changes = append(changes, change)
+		writes[name] = file.Content
+	}
+
+	for name, syncedHash := range wc.Files {
+		if _, ok := remote.Files[name]; ok {
+			continue
+		}
+		local, exists, modified := s.localState(dir, name, syncedHash)
+		if !exists {
+			continue
+		}
+		if modified && !force {
+			conflicts = append(conflicts, name)
+			continue
+		}
+		_, removed := diff.Stat(diff.Lines(local, ""))
+		changes = append(changes, domain.FileChange{Filename: name, Status: domain.FileDeleted, LinesRemoved: removed})
+		deletes = append(deletes, name)
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, domain.ErrLocalChanges{Files: conflicts}
+	}
+
+	for name, content := range writes {
+		if err := s.fs.WriteFile(workingPath(dir, name), []byte(content)); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	for _, name := range deletes {
+		if err := s.fs.RemoveAll(workingPath(dir, name)); err != nil {
+			return nil, fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+
+	if err := s.workCopy.Save(dir, domain.NewWorkingCopy(remote)); err != nil {
+		return nil, fmt.Errorf("save working copy: %w", err)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Filename < changes[j].Filename
+	})
+	return changes, nil
+}
+
+// PushGist uploads local edits in the working copy in dir to its gist.
+// Edited and new files are uploaded and tracked files deleted locally are
+// removed from the gist. The push is refused with domain.ErrRemoteConflict
+// when the gist's updated_at no longer matches the value recorded at the last
+// clone or pull. The check is optimistic: an edit landing between it and the
+// update is not detected.
+func (s *GistService) PushGist(ctx context.Context, dir string) ([]domain.FileChange, error) {
+	wc, err := s.workCopy.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := fetchFullGist(ctx, s.gistRepo, wc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get gist %s: %w", wc.ID, err)
+	}
+
+	if !remote.UpdatedAt.Equal(wc.UpdatedAt) {
+		return nil, domain.ErrRemoteConflict{ID: wc.ID, Files: remoteChanges(wc, remote)}
+	}
+
+	names, err := s.fs.ListFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", dir, err)
+	}
+
+	update := &domain.Gist{
+		ID:          remote.ID,
+		Description: remote.Description,
+		Files:       make(map[string]domain.GistFile),
+	}
+	var changes []domain.FileChange
+
+	present := make(map[string]bool)
+	for _, name := range names {
+		if !isWorkingFile(name) {
+			continue
+		}
+		present[name] = true
+
+		local, _, modified := s.localState(dir, name, wc.Files[name])
+		if !modified {
+			continue
+		}
+
+		old, tracked := remote.Files[name]
+		change := domain.FileChange{Filename: name, Status: domain.FileAdded}
+		if tracked {
+			change.Status = domain.FileModified
+		}
+		change.LinesAdded, change.LinesRemoved = diff.Stat(diff.Lines(old.Content, local))
+		changes = append(changes, change)
+		update.AddFile(name, local)
+	}
+
+	for name := range wc.Files {
+		file, ok := remote.Files[name]
+		if present[name] || !ok {
+			continue
+		}
+		_, removed := diff.Stat(diff.Lines(file.Content, ""))
+		changes = append(changes, domain.FileChange{Filename: name, Status: domain.FileDeleted, LinesRemoved: removed})
+		update.RemoveFile(name)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Filename < changes[j].Filename
+	})
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// The update response truncates large files, so the synced state is the
+	// full remote copy with the pushed files applied
+	synced := &domain.Gist{Files: make(map[string]domain.GistFile)}
+	for name, file := range remote.Files {
+		synced.Files[name] = file
+	}
+	for name, file := range update.Files {
+		if file.Deleted {
+			delete(synced.Files, name)
+		} else {
+			synced.Files[name] = file
+		}
+	}
+
+	if err := s.UpdateGist(ctx, update); err != nil {
+		return nil, err
+	}
+
+	pushed := domain.NewWorkingCopy(update)
+	pushed.Files = domain.NewWorkingCopy(synced).Files
+	if err := s.workCopy.Save(dir, pushed); err != nil {
+		return nil, fmt.Errorf("save working copy: %w", err)
+	}
+
+	return changes, nil
+}
+
+// remoteChanges lists the files whose remote content differs from what the
+// working copy last synced
+func remoteChanges(wc *domain.WorkingCopy, remote *domain.Gist) []string {
+	var files []string
+	for name, file := range remote.Files {
+		if wc.Files[name] != domain.HashContent([]byte(file.Content)) {
+			files = append(files, name)
+		}
+	}
+	for name := range wc.Files {
+		if _, ok := remote.Files[name]; !ok {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// isWorkingFile reports whether a file in a working copy belongs to the gist.
+// The metadata file, hidden files and editor backups (name~) are skipped.
+func isWorkingFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, "~")
+}
+
+// localState reads a working copy file and reports whether it exists and
+// whether it differs from the hash recorded at the last sync. A file that
+// exists but was never synced counts as modified.
+func (s *GistService) localState(dir, name, syncedHash string) (content string, exists, modified bool) {
+	path := workingPath(dir, name)
+	if !s.fs.Exists(path) {
+		// A tracked file deleted locally is a local change too
+		return "", false, syncedHash != ""
+	}
+
+	data, err := s.fs.ReadFile(path)
+	if err != nil {
+		return "", true, true
+	}
+
+	return string(data), true, domain.HashContent(data) != syncedHash
+}
+
+// workingPath returns where a gist file lives inside a working copy
+// checkWorkingName rejects gist filenames that cannot be written into a
+// working copy: names that would escape or collide in the directory, and the
+// metadata file itself
+func checkWorkingName(name string) error {
+	if err := checkBackupName(name); err != nil {
+		return err
+	}
+	if name == domain.WorkingCopyFile {
+		return fmt.Errorf("gist file %q would overwrite the working copy metadata", name)
+	}
+	return nil
+}
+
+func workingPath(dir, name string) string {
+	// Gist filenames cannot contain path separators, but never trust that
+	return filepath.Join(dir, filepath.Base(name))
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+func newWorkCopySvc(repo *fakeRepo, fs *fakeFS) (*GistService, *fakeWorkCopy) {
+	wc := &fakeWorkCopy{}
+	return NewGistService(repo, &fakeCache{}, &fakeStaging{}, wc, fs, &domain.Config{}), wc
+}
+
+func remoteGist(files map[string]string) *domain.Gist {
+	g := &domain.Gist{ID: "g1", Description: "d", UpdatedAt: time.Unix(100, 0), Files: map[string]domain.GistFile{}}
+	for name, content := range files {
+		g.Files[name] = domain.GistFile{Filename: name, Content: content}
+	}
+	return g
+}
+
+func TestCloneGist_WritesFilesAndMetadata(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A", "b.md": "B"})}
+	fs := &fakeFS{}
+	svc, store := newWorkCopySvc(repo, fs)
+
+	wc, err := svc.CloneGist(context.Background(), "g1", "dir")
+	if err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+	if string(fs.files[filepath.Join("dir", "a.md")]) != "A" || string(fs.files[filepath.Join("dir", "b.md")]) != "B" {
+		t.Errorf("files not written: %v", fs.files)
+	}
+	if wc.ID != "g1" || !wc.UpdatedAt.Equal(time.Unix(100, 0)) {
+		t.Errorf("unexpected metadata: %+v", wc)
+	}
+	if store.copies["dir"] == nil {
+		t.Error("expected metadata saved for dir")
+	}
+}
+
+func TestCloneGist_RefusesToOverwrite(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A"})}
+	fs := &fakeFS{files: map[string][]byte{filepath.Join("dir", "a.md"): []byte("mine")}}
+	svc, _ := newWorkCopySvc(repo, fs)
+
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err == nil {
+		t.Fatal("expected error when a file already exists")
+	}
+	if string(fs.files[filepath.Join("dir", "a.md")]) != "mine" {
+		t.Error("existing file must not be overwritten")
+	}
+}
+
+func TestCloneGist_RejectsUnsafeNames(t *testing.T) {
+	for _, name := range []string{domain.WorkingCopyFile, "a/x", "..", "."} {
+		repo := &fakeRepo{byID: remoteGist(map[string]string{"x": "X", name: "evil"})}
+		fs := &fakeFS{}
+		svc, store := newWorkCopySvc(repo, fs)
+
+		if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err == nil {
+			t.Errorf("%q: expected CloneGist to refuse the file", name)
+		}
+		if len(fs.files) != 0 || store.copies["dir"] != nil {
+			t.Errorf("%q: expected nothing written, got %v", name, fs.files)
+		}
+	}
+}
+
+func TestPullGist_UpdatesUnmodifiedFiles(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A", "b.md": "B"})}
+	fs := &fakeFS{}
+	svc, _ := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+
+	repo.byID = remoteGist(map[string]string{"a.md": "A2", "c.md": "C"})
+	changes, err := svc.PullGist(context.Background(), "dir", false)
+	if err != nil {
+		t.Fatalf("PullGist: %v", err)
+	}
+
+	if string(fs.files[filepath.Join("dir", "a.md")]) != "A2" {
+		t.Error("expected a.md updated")
+	}
+	if _, ok := fs.files[filepath.Join("dir", "b.md")]; ok {
+		t.Error("expected b.md deleted after it was removed remotely")
+	}
+	if string(fs.files[filepath.Join("dir", "c.md")]) != "C" {
+		t.Error("expected c.md added")
+	}
+	want := map[string]domain.FileStatus{
+		"a.md": domain.FileModified,
+		"b.md": domain.FileDeleted,
+		"c.md": domain.FileAdded,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for _, c := range changes {
+		if c.Status != want[c.Filename] {
+			t.Errorf("%s: status %s, want %s", c.Filename, c.Status, want[c.Filename])
+		}
+	}
+}
+
+func TestPullGist_RefusesLocalChanges(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A", "b.md": "B"})}
+	fs := &fakeFS{}
+	svc, _ := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+
+	fs.files[filepath.Join("dir", "a.md")] = []byte("local edit")
+	repo.byID = remoteGist(map[string]string{"a.md": "remote edit", "b.md": "B2"})
+
+	_, err := svc.PullGist(context.Background(), "dir", false)
+	var local domain.ErrLocalChanges
+	if !errors.As(err, &local) {
+		t.Fatalf("expected domain.ErrLocalChanges, got %v", err)
+	}
+	if len(local.Files) != 1 || local.Files[0] != "a.md" {
+		t.Errorf("expected only a.md reported, got %v", local.Files)
+	}
+	if string(fs.files[filepath.Join("dir", "b.md")]) != "B" {
+		t.Error("an aborted pull must not write any file")
+	}
+
+	if _, err := svc.PullGist(context.Background(), "dir", true); err != nil {
+		t.Fatalf("forced PullGist: %v", err)
+	}
+	if string(fs.files[filepath.Join("dir", "a.md")]) != "remote edit" {
+		t.Error("expected --force to overwrite the local edit")
+	}
+}
+
+func TestPullGist_NotWorkingCopy(t *testing.T) {
+	svc, _ := newWorkCopySvc(&fakeRepo{}, &fakeFS{})
+
+	_, err := svc.PullGist(context.Background(), "nowhere", false)
+	var notWC domain.ErrNotWorkingCopy
+	if !errors.As(err, &notWC) {
+		t.Fatalf("expected domain.ErrNotWorkingCopy, got %v", err)
+	}
+}
+
+func TestPushGist_UploadsLocalChanges(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A", "b.md": "B", "c.md": "C"})}
+	fs := &fakeFS{}
+	svc, store := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+
+	fs.files[filepath.Join("dir", "a.md")] = []byte("A2")
+	delete(fs.files, filepath.Join("dir", "b.md"))
+	fs.files[filepath.Join("dir", "new.md")] = []byte("N")
+	fs.files[filepath.Join("dir", ".a.md.swp")] = []byte("swap")
+
+	after := remoteGist(map[string]string{"a.md": "A2", "c.md": "C", "new.md": "N"})
+	after.UpdatedAt = time.Unix(200, 0)
+	repo.updateResult = after
+
+	changes, err := svc.PushGist(context.Background(), "dir")
+	if err != nil {
+		t.Fatalf("PushGist: %v", err)
+	}
+	if len(repo.updated) != 1 {
+		t.Fatalf("expected a single update, got %d", len(repo.updated))
+	}
+
+	want := map[string]domain.FileStatus{
+		"a.md":   domain.FileModified,
+		"b.md":   domain.FileDeleted,
+		"new.md": domain.FileAdded,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for _, c := range changes {
+		if c.Status != want[c.Filename] {
+			t.Errorf("%s: status %s, want %s", c.Filename, c.Status, want[c.Filename])
+		}
+	}
+
+	if wc := store.copies["dir"]; !wc.UpdatedAt.Equal(time.Unix(200, 0)) {
+		t.Errorf("expected recorded updated_at refreshed after push, got %v", wc.UpdatedAt)
+	}
+}
+
+func TestPushGist_TruncatedResponse(t *testing.T) {
+	big := remoteGist(map[string]string{"a.md": "A"})
+	big.Files["big.txt"] = domain.GistFile{Filename: "big.txt", RawURL: "raw/big.txt", Truncated: true, Content: "par"}
+	repo := &fakeRepo{byID: big, raw: map[string]string{"raw/big.txt": "partial and the rest"}}
+	fs := &fakeFS{}
+	svc, store := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+	fs.files[filepath.Join("dir", "a.md")] = []byte("A2")
+
+	// GitHub answers the update with big.txt truncated again
+	after := remoteGist(map[string]string{"a.md": "A2"})
+	after.Files["big.txt"] = domain.GistFile{Filename: "big.txt", RawURL: "raw/big.txt", Truncated: true, Content: "par"}
+	after.UpdatedAt = time.Unix(200, 0)
+	repo.updateResult = after
+
+	if _, err := svc.PushGist(context.Background(), "dir"); err != nil {
+		t.Fatalf("PushGist: %v", err)
+	}
+
+	wc := store.copies["dir"]
+	if wc.Files["big.txt"] != domain.HashContent([]byte("partial and the rest")) {
+		t.Error("expected the hash of the full big.txt recorded, not of the truncated response")
+	}
+	if wc.Files["a.md"] != domain.HashContent([]byte("A2")) {
+		t.Error("expected the hash of the pushed a.md recorded")
+	}
+
+	repo.byID = after
+	changes, err := svc.PushGist(context.Background(), "dir")
+	if err != nil || len(changes) != 0 || len(repo.updated) != 1 {
+		t.Errorf("expected nothing left to push, got %v err=%v after %d updates", changes, err, len(repo.updated))
+	}
+}
+
+func TestPushGist_RemoteConflict(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A", "b.md": "B"})}
+	fs := &fakeFS{}
+	svc, _ := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+	fs.files[filepath.Join("dir", "a.md")] = []byte("local")
+
+	teammate := remoteGist(map[string]string{"a.md": "A", "b.md": "teammate edit"})
+	teammate.UpdatedAt = time.Unix(300, 0)
+	repo.byID = teammate
+
+	_, err := svc.PushGist(context.Background(), "dir")
+	var conflict domain.ErrRemoteConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected domain.ErrRemoteConflict, got %v", err)
+	}
+	if len(conflict.Files) != 1 || conflict.Files[0] != "b.md" {
+		t.Errorf("expected b.md listed as changed remotely, got %v", conflict.Files)
+	}
+	if len(repo.updated) != 0 {
+		t.Error("a conflicting push must not update the gist")
+	}
+}
+
+func TestPushGist_NothingToPush(t *testing.T) {
+	repo := &fakeRepo{byID: remoteGist(map[string]string{"a.md": "A"})}
+	fs := &fakeFS{}
+	svc, _ := newWorkCopySvc(repo, fs)
+	if _, err := svc.CloneGist(context.Background(), "g1", "dir"); err != nil {
+		t.Fatalf("CloneGist: %v", err)
+	}
+
+	changes, err := svc.PushGist(context.Background(), "dir")
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected nothing to push, got %v err=%v", changes, err)
+	}
+	if len(repo.updated) != 0 {
+		t.Error("expected no update request")
+	}
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gist/internal/service"
+)
+
+// maxArchiveEntryBytes caps a single file read from a tarball so a corrupt or
+// hostile archive cannot exhaust memory.
+const maxArchiveEntryBytes = 64 * 1024 * 1024
+
+// BackupArchives implements the ArchiveRepository interface. Locations ending
+// in .tar.gz or .tgz are gzip-compressed tarballs; anything else is a
+// directory.
+type BackupArchives struct {
+	fs service.FileSystem
+}
+
+// NewBackupArchives creates a new archive repository on top of fs
+func NewBackupArchives(fs service.FileSystem) *BackupArchives {
+	return &BackupArchives{fs: fs}
+}
+
+// isTarball reports whether location names a compressed tarball
+func isTarball(location string) bool {
+	return strings.HasSuffix(location, ".tar.gz") || strings.HasSuffix(location, ".tgz")
+}
+
+// Create starts a new archive at location. Tarballs are assembled in memory
+// and written atomically on Close, so a failed backup never leaves a partial
+// archive behind.
+func (a *BackupArchives) Create(location string) (service.ArchiveWriter, error) {
+	if a.fs.Exists(location) {
+		return nil, fmt.Errorf("%s already exists", location)
+	}
+	if isTarball(location) {
+		w := &tarArchiveWriter{fs: a.fs, location: location}
+		w.gz = gzip.NewWriter(&w.buf)
+		w.tw = tar.NewWriter(w.gz)
+		return w, nil
+	}
+	return &dirArchive{fs: a.fs, dir: location}, nil
+}
+
+// Open reads an existing archive at location
+func (a *BackupArchives) Open(location string) (service.ArchiveReader, error) {
+	if !a.fs.Exists(location) {
+		return nil, os.ErrNotExist
+	}
+	if !isTarball(location) {
+		return &dirArchive{fs: a.fs, dir: location}, nil
+	}
+
+	data, err := a.fs.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	return readTarArchive(bytes.NewReader(data))
+}
+
+// dirArchive stores archive files under a directory
+type dirArchive struct {
+	fs  service.FileSystem
+	dir string
+}
+
+func (d *dirArchive) WriteFile(name string, content []byte) error {
+	return d.fs.WriteFile(d.path(name), content)
+}
+
+func (d *dirArchive) ReadFile(name string) ([]byte, error) {
+	return d.fs.ReadFile(d.path(name))
+}
+
+func (d *dirArchive) Close() error {
+	return nil
+}
+
+func (d *dirArchive) path(name string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+// tarArchiveWriter builds a gzip-compressed tarball in memory
+type tarArchiveWriter struct {
+	fs       service.FileSystem
+	location string
+	buf      bytes.Buffer
+	gz       *gzip.Writer
+	tw       *tar.Writer
+}
+
+func (t *tarArchiveWriter) WriteFile(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := t.tw.Write(content)
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	if err := t.gz.Close(); err != nil {
+		return err
+	}
+	return t.fs.WriteFile(t.location, t.buf.Bytes())
+}
+
+// tarArchive holds the regular files of a tarball in memory
+type tarArchive struct {
+	files map[string][]byte
+}
+
+func readTarArchive(r io.Reader) (*tarArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open gzip: %w", err)
+	}
+	defer gz.Close()
+
+	archive := &tarArchive{files: make(map[string][]byte)}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxArchiveEntryBytes {
+			return nil, fmt.Errorf("archive entry %s is too large: %d bytes", hdr.Name, hdr.Size)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		archive.files[path.Clean(hdr.Name)] = content
+	}
+
+	return archive, nil
+}
+
+func (t *tarArchive) ReadFile(name string) ([]byte, error) {
+	content, ok := t.files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return content, nil
+}
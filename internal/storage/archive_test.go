@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupArchives_RoundTrip(t *testing.T) {
+	for _, name := range []string{"backup.tar.gz", "backup.tgz", "backup-dir"} {
+		t.Run(name, func(t *testing.T) {
+			location := filepath.Join(t.TempDir(), name)
+			archives := NewBackupArchives(NewOSFileSystem())
+
+			w, err := archives.Create(location)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := w.WriteFile("manifest.json", []byte(`{}`)); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if err := w.WriteFile("gists/g1/a.md", []byte("hello")); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			r, err := archives.Open(location)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			got, err := r.ReadFile("gists/g1/a.md")
+			if err != nil || string(got) != "hello" {
+				t.Errorf("ReadFile = %q, %v", got, err)
+			}
+			if _, err := r.ReadFile("gists/g1/missing.md"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected os.ErrNotExist for a missing entry, got %v", err)
+			}
+
+			if _, err := archives.Create(location); err == nil {
+				t.Error("expected Create to refuse an existing location")
+			}
+		})
+	}
+}
+
+func TestBackupArchives_DirStaysInside(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backup")
+	archives := NewBackupArchives(NewOSFileSystem())
+
+	w, err := archives.Create(dir)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := w.WriteFile("../escape.txt", []byte("x")); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); err != nil {
+		t.Errorf("expected the entry to be written inside the backup dir: %v", err)
+	}
+}
@@ -0,0 +1,721 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+// boltFile is the database file inside the cache directory
+const boltFile = "cache.db"
+
+// boltOpenTimeout bounds how long an operation waits for another process
+// holding the database
+const boltOpenTimeout = 5 * time.Second
+
+// Bucket and key names of the bolt cache
+var (
+	bucketGists   = []byte("gists")
+	bucketDetails = []byte("details")
+	bucketMeta    = []byte("meta")
+
+	keyFetchedAt  = []byte("fetched_at")
+	keyFullSyncAt = []byte("full_sync_at")
+	keyValidators = []byte("validators")
+	keyLookups    = []byte("lookups")
+)
+
+// BoltCache implements the CacheRepository interface on an embedded bbolt
+// database. Listed gists and fully fetched gists (with file bodies and
+// validators) are stored per ID, so saving one gist does not rewrite the
+// others; fetch times, the list validators and the lookup counters are kept
+// in a meta bucket.
+//
+// The database is opened for each operation rather than held open, so
+// several gist processes can share it.
+type BoltCache struct {
+	cacheDir     string
+	path         string
+	maxAge       time.Duration
+	detailMaxAge time.Duration
+	retention    time.Duration
+	cleanupFreq  time.Duration
+	fs           service.FileSystem
+}
+
+// NewBoltCache creates a bolt-backed cache in cacheDir. A JSON cache left
+// in the same directory by FileCache is migrated into the database and
+// removed.
+func NewBoltCache(cacheDir string, fs service.FileSystem, config domain.CacheConfig) (*BoltCache, error) {
+	c := &BoltCache{
+		cacheDir:     cacheDir,
+		path:         filepath.Join(cacheDir, boltFile),
+		maxAge:       config.TTL,
+		detailMaxAge: config.DetailTTL,
+		retention:    detailRetention(config),
+		cleanupFreq:  config.CleanupFreq,
+		fs:           fs,
+	}
+
+	if err := c.migrate(NewFileCacheWithConfig(cacheDir, fs, config)); err != nil {
+		return nil, fmt.Errorf("migrate JSON cache: %w", err)
+	}
+
+	return c, nil
+}
+
+// exists reports whether the database file has been created. bbolt works on
+// the real file system, so this does not go through c.fs.
+func (c *BoltCache) exists() bool {
+	_, err := os.Stat(c.path)
+	return err == nil
+}
+
+// openError wraps a failure to open the database. bbolt's own file lock
+// timing out means another process kept it busy, reported the same way as a
+// held FileCache lock.
+func (c *BoltCache) openError(err error) error {
+	if errors.Is(err, bolterrors.ErrTimeout) {
+		return domain.ErrCacheLocked{Path: c.cacheDir}
+	}
+	return fmt.Errorf("open cache database: %w", err)
+}
+
+// update runs fn in a read-write transaction with every bucket created
+func (c *BoltCache) update(fn func(tx *bolt.Tx) error) error {
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return err
+	}
+
+	db, err := bolt.Open(c.path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return c.openError(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketGists, bucketDetails, bucketMeta} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+}
+
+// view runs fn in a read-only transaction. Without a database there is
+// nothing to read, which is reported as os.ErrNotExist.
+func (c *BoltCache) view(fn func(tx *bolt.Tx) error) error {
+	if !c.exists() {
+		return os.ErrNotExist
+	}
+
+	db, err := bolt.Open(c.path, 0600, &bolt.Options{Timeout: boltOpenTimeout, ReadOnly: true})
+	if err != nil {
+		return c.openError(err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketGists) == nil || tx.Bucket(bucketDetails) == nil || tx.Bucket(bucketMeta) == nil {
+			return os.ErrNotExist
+		}
+		return fn(tx)
+	})
+}
+
+// GetGists retrieves cached gists, most recently updated first
+func (c *BoltCache) GetGists() ([]domain.Gist, error) {
+	var gists []domain.Gist
+	err := c.view(func(tx *bolt.Tx) error {
+		if getTime(tx, keyFetchedAt).IsZero() {
+			return os.ErrNotExist // never listed
+		}
+
+		var err error
+		gists, err = listGists(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gists, nil
+}
+
+// SaveGists replaces the cached list along with the validators used to
+// revalidate it
+func (c *BoltCache) SaveGists(gists []domain.Gist, validators domain.CacheValidators) error {
+	return c.update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketGists); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(bucketGists)
+		if err != nil {
+			return err
+		}
+		for _, gist := range gists {
+			if err := putJSON(bucket, []byte(gist.ID), gist); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if err := putTime(tx, keyFetchedAt, now); err != nil {
+			return err
+		}
+		if err := putTime(tx, keyFullSyncAt, now); err != nil {
+			return err
+		}
+		return putJSON(tx.Bucket(bucketMeta), keyValidators, validators)
+	})
+}
+
+// GetValidators returns the validators of the cached list. They are empty
+// when there is no usable cache.
+func (c *BoltCache) GetValidators() domain.CacheValidators {
+	var validators domain.CacheValidators
+	_ = c.view(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(bucketMeta), keyValidators, &validators)
+	})
+	return validators
+}
+
+// Touch resets the fetch time of the cached list after GitHub confirmed it
+// is unchanged
+func (c *BoltCache) Touch() error {
+	if !c.exists() {
+		return os.ErrNotExist
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		if getTime(tx, keyFetchedAt).IsZero() {
+			return os.ErrNotExist
+		}
+
+		now := time.Now()
+		if err := putTime(tx, keyFetchedAt, now); err != nil {
+			return err
+		}
+		return putTime(tx, keyFullSyncAt, now)
+	})
+}
+
+// MergeGists adds or replaces gists fetched incrementally and advances the
+// fetch time to fetchedAt. Detail entries of merged gists are dropped as
+// outdated.
+func (c *BoltCache) MergeGists(gists []domain.Gist, fetchedAt time.Time) error {
+	if !c.exists() {
+		return os.ErrNotExist
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		if getTime(tx, keyFetchedAt).IsZero() {
+			return os.ErrNotExist
+		}
+
+		listed, details := tx.Bucket(bucketGists), tx.Bucket(bucketDetails)
+		for _, gist := range gists {
+			if err := putJSON(listed, []byte(gist.ID), gist); err != nil {
+				return err
+			}
+			if err := details.Delete([]byte(gist.ID)); err != nil {
+				return err
+			}
+		}
+		return putTime(tx, keyFetchedAt, fetchedAt)
+	})
+}
+
+// GetSyncState reports when the cached list was last synced. It is zero when
+// there is no usable cache.
+func (c *BoltCache) GetSyncState() domain.SyncState {
+	var state domain.SyncState
+	_ = c.view(func(tx *bolt.Tx) error {
+		state = domain.SyncState{
+			FetchedAt:  getTime(tx, keyFetchedAt),
+			FullSyncAt: getTime(tx, keyFullSyncAt),
+		}
+		return nil
+	})
+	return state
+}
+
+// PutGist adds or replaces a single gist in the cached list, keeping the
+// original fetch time, and drops its outdated detail entry. Without a
+// listed cache there is nothing to amend.
+func (c *BoltCache) PutGist(gist domain.Gist) error {
+	if !c.exists() {
+		return nil
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		if getTime(tx, keyFetchedAt).IsZero() {
+			return nil
+		}
+		if err := tx.Bucket(bucketDetails).Delete([]byte(gist.ID)); err != nil {
+			return err
+		}
+		return putJSON(tx.Bucket(bucketGists), []byte(gist.ID), gist)
+	})
+}
+
+// RemoveGist drops a single gist from the cached list and the detail cache
+func (c *BoltCache) RemoveGist(id domain.GistID) error {
+	if !c.exists() {
+		return nil
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketDetails).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketGists).Delete([]byte(id))
+	})
+}
+
+// GetGistDetail retrieves a fully fetched gist. An entry older than the
+// detail TTL is returned marked stale, for revalidation. An entry the cached
+// list shows a newer updated_at for is outdated and reported as a miss.
+func (c *BoltCache) GetGistDetail(id domain.GistID) (*domain.CachedGist, error) {
+	var detail detailPayload
+	err := c.view(func(tx *bolt.Tx) error {
+		if err := getJSON(tx.Bucket(bucketDetails), []byte(id), &detail); err != nil {
+			return err
+		}
+		if detail.Gist.ID != id || supersededIn(tx, detail.Gist) {
+			return os.ErrNotExist
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gist := detail.Gist
+	return &domain.CachedGist{
+		Gist:       &gist,
+		Validators: detail.Validators,
+		FetchedAt:  detail.FetchedAt,
+		Stale:      detail.FetchedAt.IsZero() || time.Since(detail.FetchedAt) > c.detailMaxAge,
+	}, nil
+}
+
+// SaveGistDetail caches a fully fetched gist with its response validators
+func (c *BoltCache) SaveGistDetail(gist domain.Gist, validators domain.CacheValidators) error {
+	if !gist.ID.Valid() {
+		return domain.ErrInvalidGistID{ID: string(gist.ID)}
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		detail := detailPayload{FetchedAt: time.Now(), Validators: validators, Gist: gist}
+		return putJSON(tx.Bucket(bucketDetails), []byte(gist.ID), detail)
+	})
+}
+
+// TouchGistDetail resets the fetch time of a cached gist after GitHub
+// confirmed it is unchanged
+func (c *BoltCache) TouchGistDetail(id domain.GistID) error {
+	if !c.exists() {
+		return os.ErrNotExist
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		details := tx.Bucket(bucketDetails)
+
+		var detail detailPayload
+		if err := getJSON(details, []byte(id), &detail); err != nil {
+			return err
+		}
+		detail.FetchedAt = time.Now()
+		return putJSON(details, []byte(id), detail)
+	})
+}
+
+// IsStale checks if cache needs refreshing
+func (c *BoltCache) IsStale() bool {
+	state := c.GetSyncState()
+	if state.FetchedAt.IsZero() {
+		return true
+	}
+	return time.Since(state.FetchedAt) > c.maxAge
+}
+
+// Clear removes all cached data
+func (c *BoltCache) Clear() error {
+	if !c.exists() {
+		return nil
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketGists, bucketDetails, bucketMeta} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecordLookups adds to the counts of gist lookups that were cache hits
+// and misses
+func (c *BoltCache) RecordLookups(hits, misses int) error {
+	return c.update(func(tx *bolt.Tx) error {
+		stats := getLookups(tx)
+		stats.Hits += hits
+		stats.Misses += misses
+		return putJSON(tx.Bucket(bucketMeta), keyLookups, stats)
+	})
+}
+
+// ResetCounters restarts the lookup counters from now
+func (c *BoltCache) ResetCounters() error {
+	return c.update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketMeta), keyLookups, statsPayload{Since: time.Now()})
+	})
+}
+
+// Stats summarizes the cached list, the detail cache and the lookup
+// counters. Size is that of the whole database file.
+func (c *BoltCache) Stats() (*domain.CacheStats, error) {
+	stats := &domain.CacheStats{Backend: domain.CacheBackendBolt, Dir: c.cacheDir}
+	err := c.view(func(tx *bolt.Tx) error {
+		stats.Listed = tx.Bucket(bucketGists).Stats().KeyN
+		stats.FetchedAt = getTime(tx, keyFetchedAt)
+		stats.FullSyncAt = getTime(tx, keyFullSyncAt)
+
+		err := tx.Bucket(bucketDetails).ForEach(func(_, v []byte) error {
+			var detail detailPayload
+			if err := json.Unmarshal(v, &detail); err != nil {
+				return nil
+			}
+			stats.Details++
+			if stats.OldestDetail.IsZero() || detail.FetchedAt.Before(stats.OldestDetail) {
+				stats.OldestDetail = detail.FetchedAt
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		counters := getLookups(tx)
+		stats.Hits, stats.Misses, stats.CountingSince = counters.Hits, counters.Misses, counters.Since
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if info, err := os.Stat(c.path); err == nil {
+		stats.Size = info.Size()
+	}
+	return stats, nil
+}
+
+// Entries lists the gists of the cached list followed by the fully fetched
+// ones, with the size of their stored JSON
+func (c *BoltCache) Entries() ([]domain.CacheEntry, error) {
+	var entries []domain.CacheEntry
+	err := c.view(func(tx *bolt.Tx) error {
+		fetchedAt := getTime(tx, keyFetchedAt)
+		err := tx.Bucket(bucketGists).ForEach(func(_, v []byte) error {
+			var gist domain.Gist
+			if err := json.Unmarshal(v, &gist); err != nil {
+				return nil
+			}
+			entries = append(entries, domain.CacheEntry{
+				ID:          gist.ID,
+				Description: gist.Description,
+				FetchedAt:   fetchedAt,
+				Size:        int64(len(v)),
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(bucketDetails).ForEach(func(_, v []byte) error {
+			var detail detailPayload
+			if err := json.Unmarshal(v, &detail); err != nil {
+				return nil
+			}
+			entries = append(entries, domain.CacheEntry{
+				ID:          detail.Gist.ID,
+				Description: detail.Gist.Description,
+				Detail:      true,
+				FetchedAt:   detail.FetchedAt,
+				Size:        int64(len(v)),
+			})
+			return nil
+		})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// Prune removes the detail entries fetched before cutoff, and the cached
+// list if it was. Unreadable detail entries are removed too. It returns the
+// number of gists dropped from the list and the detail cache.
+func (c *BoltCache) Prune(cutoff time.Time) (int, error) {
+	if !c.exists() {
+		return 0, nil
+	}
+
+	removed := 0
+	err := c.update(func(tx *bolt.Tx) error {
+		details := tx.Bucket(bucketDetails)
+
+		var expired [][]byte
+		err := details.ForEach(func(k, v []byte) error {
+			var detail detailPayload
+			if err := json.Unmarshal(v, &detail); err != nil || detail.FetchedAt.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := details.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+
+		fetchedAt := getTime(tx, keyFetchedAt)
+		if fetchedAt.IsZero() || !fetchedAt.Before(cutoff) {
+			return nil
+		}
+		removed += tx.Bucket(bucketGists).Stats().KeyN
+		if err := tx.DeleteBucket(bucketGists); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucketGists); err != nil {
+			return err
+		}
+		meta := tx.Bucket(bucketMeta)
+		for _, key := range [][]byte{keyFetchedAt, keyFullSyncAt, keyValidators} {
+			if err := meta.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// StartCleanup starts periodic cleanup of expired detail entries
+func (c *BoltCache) StartCleanup(ctx context.Context) {
+	if c.cleanupFreq <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.cleanupFreq)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.cleanup(); err != nil {
+					// Log error but don't crash
+					continue
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// cleanup removes detail entries that are past the detail retention,
+// unreadable, or outdated by the cached list. Entries that are only stale are
+// kept to be revalidated or read offline.
+func (c *BoltCache) cleanup() error {
+	if !c.exists() {
+		return nil
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		details := tx.Bucket(bucketDetails)
+
+		var expired [][]byte
+		err := details.ForEach(func(k, v []byte) error {
+			var detail detailPayload
+			if err := json.Unmarshal(v, &detail); err != nil ||
+				time.Since(detail.FetchedAt) > c.retention || supersededIn(tx, detail.Gist) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := details.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrate imports a JSON cache left by FileCache, then removes it. A
+// database that already exists is never overwritten.
+func (c *BoltCache) migrate(legacy *FileCache) error {
+	if c.exists() || !c.fs.Exists(legacy.cacheFile) {
+		return nil
+	}
+
+	// Processes still on the file backend must not write while the JSON
+	// cache is copied and removed
+	return legacy.withLock(true, func() error {
+		if c.exists() {
+			return nil // migrated by another process meanwhile
+		}
+		return c.importLegacy(legacy)
+	})
+}
+
+// importLegacy copies the JSON cache of legacy into the database and removes
+// it. The caller holds the legacy cache lock.
+func (c *BoltCache) importLegacy(legacy *FileCache) error {
+	payload, ok, err := legacy.readPayload()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	var details []detailPayload
+	if names, err := c.fs.ListFiles(legacy.detailDir); err == nil {
+		for _, name := range names {
+			if filepath.Ext(name) != ".json" {
+				continue
+			}
+			detail, ok, err := legacy.readDetail(domain.GistID(name[:len(name)-len(".json")]))
+			if err == nil && ok {
+				details = append(details, detail)
+			}
+		}
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		for _, gist := range payload.Gists {
+			if err := putJSON(tx.Bucket(bucketGists), []byte(gist.ID), gist); err != nil {
+				return err
+			}
+		}
+		for _, detail := range details {
+			if err := putJSON(tx.Bucket(bucketDetails), []byte(detail.Gist.ID), detail); err != nil {
+				return err
+			}
+		}
+		if err := putTime(tx, keyFetchedAt, payload.FetchedAt); err != nil {
+			return err
+		}
+		if err := putTime(tx, keyFullSyncAt, payload.FullSyncAt); err != nil {
+			return err
+		}
+		return putJSON(tx.Bucket(bucketMeta), keyValidators, payload.Validators)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.fs.RemoveAll(legacy.cacheFile); err != nil {
+		return err
+	}
+	return c.fs.RemoveAll(legacy.detailDir)
+}
+
+// listGists decodes the gists bucket, most recently updated first
+func listGists(tx *bolt.Tx) ([]domain.Gist, error) {
+	gists := []domain.Gist{}
+	err := tx.Bucket(bucketGists).ForEach(func(_, v []byte) error {
+		var gist domain.Gist
+		if err := json.Unmarshal(v, &gist); err != nil {
+			return err
+		}
+		gists = append(gists, gist)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(gists, func(i, j int) bool {
+		return gists[i].UpdatedAt.After(gists[j].UpdatedAt)
+	})
+	return gists, nil
+}
+
+// supersededIn reports whether the gists bucket holds a newer version of
+// gist than its detail entry
+func supersededIn(tx *bolt.Tx, gist domain.Gist) bool {
+	var listed domain.Gist
+	if err := getJSON(tx.Bucket(bucketGists), []byte(gist.ID), &listed); err != nil {
+		return false
+	}
+	return listed.UpdatedAt.After(gist.UpdatedAt)
+}
+
+// getLookups decodes the lookup counters. Missing or unreadable counters
+// start over from now.
+func getLookups(tx *bolt.Tx) statsPayload {
+	var stats statsPayload
+	_ = getJSON(tx.Bucket(bucketMeta), keyLookups, &stats)
+	if stats.Since.IsZero() {
+		stats = statsPayload{Since: time.Now()}
+	}
+	return stats
+}
+
+// putJSON stores v as JSON under key
+func putJSON(bucket *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// getJSON decodes the JSON stored under key into v, or returns
+// os.ErrNotExist when the key is absent
+func getJSON(bucket *bolt.Bucket, key []byte, v any) error {
+	data := bucket.Get(key)
+	if data == nil {
+		return os.ErrNotExist
+	}
+	return json.Unmarshal(data, v)
+}
+
+// putTime stores a time in the meta bucket
+func putTime(tx *bolt.Tx, key []byte, t time.Time) error {
+	return putJSON(tx.Bucket(bucketMeta), key, t)
+}
+
+// getTime reads a time from the meta bucket, zero when unset
+func getTime(tx *bolt.Tx, key []byte) time.Time {
+	var t time.Time
+	_ = getJSON(tx.Bucket(bucketMeta), key, &t)
+	return t
+}
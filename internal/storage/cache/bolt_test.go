@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+	bolt "go.etcd.io/bbolt"
+)
+
+// compile-time interface checks.
+var (
+	_ service.CacheRepository = (*BoltCache)(nil)
+	_ Cache                   = (*BoltCache)(nil)
+	_ Cache                   = (*FileCache)(nil)
+)
+
+func newTestBoltCache(t *testing.T, fs service.FileSystem) *BoltCache {
+	t.Helper()
+	c, err := NewBoltCache(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewBoltCache: %v", err)
+	}
+	return c
+}
+
+func TestBoltCache_ListRoundTrip(t *testing.T) {
+	c := newTestBoltCache(t, newMemFS())
+
+	if _, err := c.GetGists(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a miss on an empty cache, got %v", err)
+	}
+	if !c.IsStale() {
+		t.Error("empty cache should be stale")
+	}
+	if err := c.Touch(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected Touch to fail without a listing, got %v", err)
+	}
+	if err := c.PutGist(domain.Gist{ID: "early"}); err != nil {
+		t.Errorf("PutGist without a listing should be a no-op, got %v", err)
+	}
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	etag := domain.CacheValidators{ETag: `"list"`}
+	gists := []domain.Gist{{ID: "a", UpdatedAt: older}, {ID: "b", UpdatedAt: older.Add(time.Hour)}}
+	if err := c.SaveGists(gists, etag); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if c.IsStale() {
+		t.Error("cache should be fresh right after save")
+	}
+	if got := c.GetValidators(); got != etag {
+		t.Errorf("GetValidators = %+v", got)
+	}
+	got, err := c.GetGists()
+	if err != nil {
+		t.Fatalf("GetGists: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "b" {
+		t.Errorf("expected most recently updated first, got %+v", got)
+	}
+
+	full := c.GetSyncState()
+	fetchedAt := full.FetchedAt.Add(time.Minute)
+	if err := c.MergeGists([]domain.Gist{{ID: "c", UpdatedAt: older.Add(2 * time.Hour)}}, fetchedAt); err != nil {
+		t.Fatalf("MergeGists: %v", err)
+	}
+	state := c.GetSyncState()
+	if !state.FetchedAt.Equal(fetchedAt) || !state.FullSyncAt.Equal(full.FullSyncAt) {
+		t.Errorf("expected only FetchedAt to advance, got %+v", state)
+	}
+
+	if err := c.PutGist(domain.Gist{ID: "a", Description: "edited", UpdatedAt: older}); err != nil {
+		t.Fatalf("PutGist: %v", err)
+	}
+	if err := c.RemoveGist("b"); err != nil {
+		t.Fatalf("RemoveGist: %v", err)
+	}
+	got, _ = c.GetGists()
+	if len(got) != 2 || got[0].ID != "c" || got[1].Description != "edited" {
+		t.Errorf("unexpected list after merge/put/remove: %+v", got)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := c.GetGists(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a miss after Clear, got %v", err)
+	}
+}
+
+func TestBoltCache_GistDetail(t *testing.T) {
+	c := newTestBoltCache(t, newMemFS())
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	etag := domain.CacheValidators{ETag: `"g1"`}
+	gist := domain.Gist{ID: "g1", UpdatedAt: older, Files: map[string]domain.GistFile{"a.md": {Content: "body"}}}
+	if err := c.SaveGistDetail(gist, etag); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+
+	got, err := c.GetGistDetail("g1")
+	if err != nil {
+		t.Fatalf("GetGistDetail: %v", err)
+	}
+	if got.Stale || got.Validators != etag || got.Gist.Files["a.md"].Content != "body" {
+		t.Errorf("unexpected detail: %+v", got)
+	}
+	if err := c.TouchGistDetail("g1"); err != nil {
+		t.Errorf("TouchGistDetail: %v", err)
+	}
+
+	if err := c.SaveGists([]domain.Gist{{ID: "g1", UpdatedAt: older.Add(time.Hour)}}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if _, err := c.GetGistDetail("g1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a miss once the list shows a newer updated_at, got %v", err)
+	}
+	if err := c.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if err := c.TouchGistDetail("g1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected cleanup to drop the outdated entry, got %v", err)
+	}
+}
+
+func TestBoltCache_CleanupKeepsStaleDetails(t *testing.T) {
+	c, err := NewBoltCache(t.TempDir(), newMemFS(), domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Minute, DetailRetention: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewBoltCache: %v", err)
+	}
+	err = c.update(func(tx *bolt.Tx) error {
+		for id, age := range map[string]time.Duration{"stale": time.Hour, "old": 48 * time.Hour} {
+			detail := detailPayload{FetchedAt: time.Now().Add(-age), Gist: domain.Gist{ID: domain.GistID(id)}}
+			if err := putJSON(tx.Bucket(bucketDetails), []byte(id), detail); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed details: %v", err)
+	}
+
+	if err := c.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if detail, err := c.GetGistDetail("stale"); err != nil || !detail.Stale {
+		t.Errorf("expected the stale entry kept and marked stale, got %+v err=%v", detail, err)
+	}
+	if _, err := c.GetGistDetail("old"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the entry past retention dropped, got %v", err)
+	}
+}
+
+func TestBoltCache_MigratesJSONCache(t *testing.T) {
+	fs := newMemFS()
+	dir := t.TempDir()
+	legacy := NewFileCacheWithConfig(dir, fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+
+	etag := domain.CacheValidators{ETag: `"legacy"`}
+	if err := legacy.SaveGists([]domain.Gist{{ID: "g1", Description: "migrated"}}, etag); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if err := legacy.SaveGistDetail(domain.Gist{ID: "g1", Description: "migrated"}, etag); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+	state := legacy.GetSyncState()
+
+	c, err := NewBoltCache(dir, fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("NewBoltCache: %v", err)
+	}
+
+	got, err := c.GetGists()
+	if err != nil || len(got) != 1 || got[0].Description != "migrated" {
+		t.Fatalf("expected migrated list, got %+v err=%v", got, err)
+	}
+	if c.GetValidators() != etag || !c.GetSyncState().FetchedAt.Equal(state.FetchedAt) {
+		t.Errorf("expected validators and sync state migrated, got %+v %+v", c.GetValidators(), c.GetSyncState())
+	}
+	if detail, err := c.GetGistDetail("g1"); err != nil || detail.Gist.Description != "migrated" {
+		t.Errorf("expected migrated detail, got %+v err=%v", detail, err)
+	}
+	if fs.Exists(legacy.cacheFile) || fs.Exists(filepath.Join(legacy.detailDir, "g1.json")) {
+		t.Error("expected JSON cache removed after migration")
+	}
+
+	// A later JSON cache must not overwrite the database.
+	data, _ := json.Marshal(cachePayload{FetchedAt: time.Now(), Gists: []domain.Gist{{ID: "other"}}})
+	fs.files[legacy.cacheFile] = data
+	if _, err := NewBoltCache(dir, fs, domain.CacheConfig{TTL: time.Minute}); err != nil {
+		t.Fatalf("NewBoltCache: %v", err)
+	}
+	if got, _ := c.GetGists(); len(got) != 1 || got[0].ID != "g1" {
+		t.Errorf("existing database was overwritten: %+v", got)
+	}
+}
+
+func TestOpen_SelectsBackend(t *testing.T) {
+	dir := t.TempDir()
+
+	if c, err := Open(dir, newMemFS(), domain.CacheConfig{}); err != nil {
+		t.Fatalf("Open default: %v", err)
+	} else if _, ok := c.(*FileCache); !ok {
+		t.Errorf("expected FileCache by default, got %T", c)
+	}
+	if c, err := Open(dir, newMemFS(), domain.CacheConfig{Backend: domain.CacheBackendBolt}); err != nil {
+		t.Fatalf("Open bolt: %v", err)
+	} else if _, ok := c.(*BoltCache); !ok {
+		t.Errorf("expected BoltCache, got %T", c)
+	}
+	if _, err := Open(dir, newMemFS(), domain.CacheConfig{Backend: "redis"}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+)
+
+// Cache is a CacheRepository that also expires its entries in the background
+type Cache interface {
+	service.CacheRepository
+
+	// StartCleanup starts periodic cleanup of expired cache entries
+	StartCleanup(ctx context.Context)
+}
+
+// Open returns the cache backend selected by config.Backend, defaulting to
+// the JSON file cache
+func Open(cacheDir string, fs service.FileSystem, config domain.CacheConfig) (Cache, error) {
+	switch config.Backend {
+	case "", domain.CacheBackendFile:
+		return NewFileCacheWithConfig(cacheDir, fs, config), nil
+	case domain.CacheBackendBolt:
+		return NewBoltCache(cacheDir, fs, config)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (want %s or %s)", config.Backend, domain.CacheBackendFile, domain.CacheBackendBolt)
+	}
+}
+
+// detailRetention returns how long cleanup keeps gist details, which is
+// never shorter than the detail TTL
+func detailRetention(config domain.CacheConfig) time.Duration {
+	retention := config.DetailRetention
+	if retention <= 0 {
+		retention = domain.DefaultDetailRetention
+	}
+	return max(retention, config.DetailTTL)
+}
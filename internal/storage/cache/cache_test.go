@@ -2,7 +2,10 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -41,9 +44,19 @@ func (m *memFS) Size(path string) (int64, error) {
 	return int64(len(b)), nil
 }
 
+func (m *memFS) ListFiles(dir string) ([]string, error) {
+	var names []string
+	for k := range m.files {
+		if filepath.Dir(k) == dir {
+			names = append(names, filepath.Base(k))
+		}
+	}
+	return names, nil
+}
+
 func (m *memFS) RemoveAll(path string) error {
 	for k := range m.files {
-		if k == path {
+		if k == path || strings.HasPrefix(k, path+string(filepath.Separator)) {
 			delete(m.files, k)
 		}
 	}
@@ -61,7 +74,7 @@ func TestFileCache_SaveGet_RoundTrip(t *testing.T) {
 	fs := newMemFS()
 	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: 5 * time.Minute})
 
-	if err := c.SaveGists(sampleGists()); err != nil {
+	if err := c.SaveGists(sampleGists(), domain.CacheValidators{}); err != nil {
 		t.Fatalf("SaveGists: %v", err)
 	}
 	if c.IsStale() {
@@ -97,6 +110,45 @@ func TestFileCache_IsStale_Expired(t *testing.T) {
 	}
 }
 
+func TestFileCache_Validators_TouchRefreshes(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute})
+
+	if !c.GetValidators().Empty() {
+		t.Error("expected no validators without a cache")
+	}
+	if err := c.Touch(); err == nil {
+		t.Error("expected Touch to fail without a cache")
+	}
+
+	etag := domain.CacheValidators{ETag: `W/"abc"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+	old := cachePayload{FetchedAt: time.Now().Add(-10 * time.Minute), Validators: etag, Gists: sampleGists()}
+	data, err := json.Marshal(old)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	fs.files[c.cacheFile] = data
+
+	if got := c.GetValidators(); got != etag {
+		t.Errorf("GetValidators = %+v, want %+v", got, etag)
+	}
+	if !c.IsStale() {
+		t.Fatal("cache with old FetchedAt should be stale")
+	}
+	if err := c.Touch(); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if c.IsStale() {
+		t.Error("cache should be fresh after Touch")
+	}
+	if got := c.GetValidators(); got != etag {
+		t.Errorf("Touch changed validators: %+v", got)
+	}
+	if got, _ := c.GetGists(); len(got) != 1 {
+		t.Errorf("Touch changed gists: %+v", got)
+	}
+}
+
 func TestFileCache_LegacyBareArray_TreatedAsMiss(t *testing.T) {
 	fs := newMemFS()
 	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: 5 * time.Minute})
@@ -127,3 +179,312 @@ func TestFileCache_IsStale_NoFile(t *testing.T) {
 		t.Error("expected miss error when no cache file")
 	}
 }
+
+func TestFileCache_RemoveGist_KeepsFetchedAt(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute})
+
+	fetched := time.Now().Add(-30 * time.Second).UTC().Truncate(time.Second)
+	payload := cachePayload{FetchedAt: fetched, Gists: []domain.Gist{{ID: "keep"}, {ID: "drop"}}}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	fs.files[c.cacheFile] = data
+
+	if err := c.RemoveGist("drop"); err != nil {
+		t.Fatalf("RemoveGist: %v", err)
+	}
+	got, err := c.GetGists()
+	if err != nil {
+		t.Fatalf("GetGists: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "keep" {
+		t.Errorf("expected only 'keep' to remain, got %+v", got)
+	}
+
+	var after cachePayload
+	if err := json.Unmarshal(fs.files[c.cacheFile], &after); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !after.FetchedAt.Equal(fetched) {
+		t.Errorf("FetchedAt changed: want %v, got %v", fetched, after.FetchedAt)
+	}
+}
+
+func TestFileCache_RemoveGist_NoFile(t *testing.T) {
+	c := NewFileCacheWithConfig(t.TempDir(), newMemFS(), domain.CacheConfig{TTL: time.Minute})
+
+	if err := c.RemoveGist("missing"); err != nil {
+		t.Errorf("removing from an empty cache should be a no-op, got %v", err)
+	}
+}
+
+func TestFileCache_PutGist_ReplacesAndAdds(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute})
+
+	if err := c.SaveGists([]domain.Gist{{ID: "a", Description: "old"}}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if err := c.PutGist(domain.Gist{ID: "a", Description: "new"}); err != nil {
+		t.Fatalf("PutGist replace: %v", err)
+	}
+	if err := c.PutGist(domain.Gist{ID: "b"}); err != nil {
+		t.Fatalf("PutGist add: %v", err)
+	}
+
+	got, err := c.GetGists()
+	if err != nil {
+		t.Fatalf("GetGists: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 gists, got %+v", got)
+	}
+	for _, g := range got {
+		if g.ID == "a" && g.Description != "new" {
+			t.Errorf("expected gist a replaced, got %+v", g)
+		}
+	}
+}
+
+func TestFileCache_GistDetail_RoundTripAndTTL(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Minute})
+
+	if _, err := c.GetGistDetail("g1"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a miss for an uncached gist, got %v", err)
+	}
+
+	etag := domain.CacheValidators{ETag: `"g1"`}
+	gist := domain.Gist{ID: "g1", Files: map[string]domain.GistFile{"a.md": {Content: "body"}}}
+	if err := c.SaveGistDetail(gist, etag); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+	path := filepath.Join(c.cacheDir, "gists", "g1.json")
+	if _, ok := fs.files[path]; !ok {
+		t.Fatalf("expected detail entry at %s", path)
+	}
+
+	got, err := c.GetGistDetail("g1")
+	if err != nil {
+		t.Fatalf("GetGistDetail: %v", err)
+	}
+	if got.Stale || got.Validators != etag || got.Gist.Files["a.md"].Content != "body" {
+		t.Errorf("unexpected detail: %+v", got)
+	}
+
+	old := detailPayload{FetchedAt: time.Now().Add(-time.Hour), Validators: etag, Gist: gist}
+	data, _ := json.Marshal(old)
+	fs.files[path] = data
+	if got, err := c.GetGistDetail("g1"); err != nil || !got.Stale {
+		t.Fatalf("expected a stale entry past the detail TTL, got %+v err=%v", got, err)
+	}
+	if err := c.TouchGistDetail("g1"); err != nil {
+		t.Fatalf("TouchGistDetail: %v", err)
+	}
+	if got, err := c.GetGistDetail("g1"); err != nil || got.Stale {
+		t.Errorf("expected a fresh entry after touch, got %+v err=%v", got, err)
+	}
+}
+
+func TestFileCache_GistDetail_SupersededByList(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := c.SaveGistDetail(domain.Gist{ID: "g1", UpdatedAt: older}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+	if err := c.SaveGists([]domain.Gist{{ID: "g1", UpdatedAt: older}}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if _, err := c.GetGistDetail("g1"); err != nil {
+		t.Fatalf("detail matching the list should be served, got %v", err)
+	}
+
+	if err := c.SaveGists([]domain.Gist{{ID: "g1", UpdatedAt: older.Add(time.Hour)}}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	if _, err := c.GetGistDetail("g1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a miss once the list shows a newer updated_at, got %v", err)
+	}
+}
+
+func TestFileCache_PutAndRemoveGist_DropDetail(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+
+	for _, id := range []domain.GistID{"put", "removed"} {
+		if err := c.SaveGistDetail(domain.Gist{ID: id}, domain.CacheValidators{}); err != nil {
+			t.Fatalf("SaveGistDetail: %v", err)
+		}
+	}
+	if err := c.PutGist(domain.Gist{ID: "put"}); err != nil {
+		t.Fatalf("PutGist: %v", err)
+	}
+	if err := c.RemoveGist("removed"); err != nil {
+		t.Fatalf("RemoveGist: %v", err)
+	}
+	for _, id := range []domain.GistID{"put", "removed"} {
+		if _, err := c.GetGistDetail(id); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected detail of %s dropped, got %v", id, err)
+		}
+	}
+}
+
+func TestFileCache_CleanupExpiresDetails(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Minute, DetailRetention: 24 * time.Hour})
+
+	if err := c.SaveGistDetail(domain.Gist{ID: "fresh"}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+	for id, age := range map[string]time.Duration{"stale": time.Hour, "old": 48 * time.Hour} {
+		data, _ := json.Marshal(detailPayload{FetchedAt: time.Now().Add(-age), Gist: domain.Gist{ID: domain.GistID(id)}})
+		fs.files[filepath.Join(c.detailDir, id+".json")] = data
+	}
+	fs.files[filepath.Join(c.detailDir, "junk.json")] = []byte("not json")
+
+	if err := c.cleanupDetails(); err != nil {
+		t.Fatalf("cleanupDetails: %v", err)
+	}
+	if _, ok := fs.files[filepath.Join(c.detailDir, "fresh.json")]; !ok {
+		t.Error("fresh entry should be kept")
+	}
+
+	// A stale entry is kept for revalidation and offline reads
+	detail, err := c.GetGistDetail("stale")
+	if err != nil || !detail.Stale {
+		t.Errorf("expected the stale entry kept and marked stale, got %+v err=%v", detail, err)
+	}
+	for _, name := range []string{"old.json", "junk.json"} {
+		if _, ok := fs.files[filepath.Join(c.detailDir, name)]; ok {
+			t.Errorf("%s should have been removed", name)
+		}
+	}
+}
+
+func TestFileCache_MergeGists_KeepsFullSyncTime(t *testing.T) {
+	fs := newMemFS()
+	c := NewFileCacheWithConfig(t.TempDir(), fs, domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+
+	if err := c.MergeGists(sampleGists(), time.Now()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a miss merging into no cache, got %v", err)
+	}
+
+	if err := c.SaveGists([]domain.Gist{{ID: "a", Description: "old"}, {ID: "b"}}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	full := c.GetSyncState()
+	if full.FetchedAt.IsZero() || !full.FullSyncAt.Equal(full.FetchedAt) {
+		t.Fatalf("expected a full listing to set both times, got %+v", full)
+	}
+	if err := c.SaveGistDetail(domain.Gist{ID: "a"}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+
+	fetchedAt := full.FetchedAt.Add(time.Minute)
+	if err := c.MergeGists([]domain.Gist{{ID: "c"}, {ID: "a", Description: "new"}}, fetchedAt); err != nil {
+		t.Fatalf("MergeGists: %v", err)
+	}
+
+	got, err := c.GetGists()
+	if err != nil {
+		t.Fatalf("GetGists: %v", err)
+	}
+	var ids []domain.GistID
+	for _, g := range got {
+		ids = append(ids, g.ID)
+	}
+	if len(got) != 3 || ids[0] != "c" || ids[1] != "a" || ids[2] != "b" || got[1].Description != "new" {
+		t.Errorf("unexpected merged list: %+v", got)
+	}
+
+	state := c.GetSyncState()
+	if !state.FetchedAt.Equal(fetchedAt) || !state.FullSyncAt.Equal(full.FullSyncAt) {
+		t.Errorf("expected only FetchedAt to advance, got %+v", state)
+	}
+	if _, err := c.GetGistDetail("a"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the merged gist's detail entry dropped, got %v", err)
+	}
+}
+
+func TestCaches_StatsEntriesPrune(t *testing.T) {
+	backends := map[string]func(t *testing.T) service.CacheRepository{
+		"file": func(t *testing.T) service.CacheRepository {
+			return NewFileCacheWithConfig(t.TempDir(), newMemFS(), domain.CacheConfig{TTL: time.Minute, DetailTTL: time.Hour})
+		},
+		"bolt": func(t *testing.T) service.CacheRepository { return newTestBoltCache(t, newMemFS()) },
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			c := open(t)
+
+			if stats, err := c.Stats(); err != nil || stats.Listed != 0 || stats.Details != 0 {
+				t.Fatalf("expected empty stats, got %+v err=%v", stats, err)
+			}
+
+			for _, counts := range [][2]int{{1, 0}, {1, 1}} {
+				if err := c.RecordLookups(counts[0], counts[1]); err != nil {
+					t.Fatalf("RecordLookups: %v", err)
+				}
+			}
+			if err := c.SaveGists([]domain.Gist{{ID: "a", Description: "first"}, {ID: "b"}}, domain.CacheValidators{}); err != nil {
+				t.Fatalf("SaveGists: %v", err)
+			}
+			if err := c.SaveGistDetail(domain.Gist{ID: "a", Description: "first"}, domain.CacheValidators{}); err != nil {
+				t.Fatalf("SaveGistDetail: %v", err)
+			}
+
+			stats, err := c.Stats()
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if stats.Listed != 2 || stats.Details != 1 || stats.Size == 0 || stats.FetchedAt.IsZero() || stats.OldestDetail.IsZero() {
+				t.Errorf("unexpected contents in stats: %+v", stats)
+			}
+			if stats.Hits != 2 || stats.Misses != 1 || stats.CountingSince.IsZero() {
+				t.Errorf("unexpected counters in stats: %+v", stats)
+			}
+
+			entries, err := c.Entries()
+			if err != nil {
+				t.Fatalf("Entries: %v", err)
+			}
+			details := 0
+			for _, entry := range entries {
+				if entry.Detail {
+					details++
+				}
+				if entry.Size == 0 || entry.FetchedAt.IsZero() {
+					t.Errorf("incomplete entry %+v", entry)
+				}
+			}
+			if len(entries) != 3 || details != 1 {
+				t.Errorf("expected 2 listed and 1 fetched entry, got %+v", entries)
+			}
+
+			if err := c.ResetCounters(); err != nil {
+				t.Fatalf("ResetCounters: %v", err)
+			}
+			if stats, _ := c.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+				t.Errorf("expected counters reset, got %+v", stats)
+			}
+
+			if removed, err := c.Prune(time.Now().Add(-time.Hour)); err != nil || removed != 0 {
+				t.Errorf("expected nothing pruned, got %d err=%v", removed, err)
+			}
+			if removed, err := c.Prune(time.Now().Add(time.Minute)); err != nil || removed != 3 {
+				t.Errorf("expected everything pruned, got %d err=%v", removed, err)
+			}
+			if _, err := c.GetGists(); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected the list pruned, got %v", err)
+			}
+			if entries, _ := c.Entries(); len(entries) != 0 {
+				t.Errorf("expected no entries left, got %+v", entries)
+			}
+		})
+	}
+}
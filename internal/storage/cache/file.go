@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,27 +13,59 @@ import (
 	"gist/internal/service"
 )
 
-// FileCache implements caching using local JSON files
+// FileCache implements caching using local JSON files: the gist list in
+// gists.json, fully fetched gists in gists/<id>.json and lookup counters in
+// stats.json
+//
+// Every operation holds an advisory lock on the .lock file in the cache
+// directory, shared for reads and exclusive for writes and cleanup, so
+// several gist processes can use the same cache.
 type FileCache struct {
-	cacheDir    string
-	cacheFile   string
-	maxAge      time.Duration
-	cleanupFreq time.Duration
-	fs          service.FileSystem
+	cacheDir     string
+	cacheFile    string
+	detailDir    string
+	statsFile    string
+	lockPath     string
+	lockTimeout  time.Duration
+	maxAge       time.Duration
+	detailMaxAge time.Duration
+	retention    time.Duration
+	cleanupFreq  time.Duration
+	fs           service.FileSystem
 }
 
 // cachePayload wraps the cached gists with the time they were fetched so
 // freshness can be judged from stored data rather than file mtime (which is
 // unreliable under touch or clock skew).
+//
+// FullSyncAt is the time of the last complete listing. Incremental syncs
+// advance FetchedAt only, since they cannot see deleted gists.
 type cachePayload struct {
-	FetchedAt time.Time     `json:"fetched_at"`
-	Gists     []domain.Gist `json:"gists"`
+	FetchedAt  time.Time              `json:"fetched_at"`
+	FullSyncAt time.Time              `json:"full_sync_at"`
+	Validators domain.CacheValidators `json:"validators,omitempty"`
+	Gists      []domain.Gist          `json:"gists"`
+}
+
+// detailPayload is a single fully fetched gist in the detail cache
+type detailPayload struct {
+	FetchedAt  time.Time              `json:"fetched_at"`
+	Validators domain.CacheValidators `json:"validators,omitempty"`
+	Gist       domain.Gist            `json:"gist"`
+}
+
+// statsPayload holds the lookup counters since a point in time
+type statsPayload struct {
+	Since  time.Time `json:"since"`
+	Hits   int       `json:"hits"`
+	Misses int       `json:"misses"`
 }
 
 // NewFileCache creates a new file-based cache with default settings
 func NewFileCache(cacheDir string, fs service.FileSystem) *FileCache {
 	return NewFileCacheWithConfig(cacheDir, fs, domain.CacheConfig{
 		TTL:         5 * time.Minute,
+		DetailTTL:   10 * time.Minute,
 		CleanupFreq: time.Hour,
 	})
 }
@@ -40,42 +73,370 @@ func NewFileCache(cacheDir string, fs service.FileSystem) *FileCache {
 // NewFileCacheWithConfig creates a new file-based cache with specified configuration
 func NewFileCacheWithConfig(cacheDir string, fs service.FileSystem, config domain.CacheConfig) *FileCache {
 	return &FileCache{
-		cacheDir:    cacheDir,
-		cacheFile:   filepath.Join(cacheDir, "gists.json"),
-		maxAge:      config.TTL,
-		cleanupFreq: config.CleanupFreq,
-		fs:          fs,
+		cacheDir:     cacheDir,
+		cacheFile:    filepath.Join(cacheDir, "gists.json"),
+		detailDir:    filepath.Join(cacheDir, "gists"),
+		statsFile:    filepath.Join(cacheDir, "stats.json"),
+		lockPath:     filepath.Join(cacheDir, lockFile),
+		lockTimeout:  lockTimeout,
+		maxAge:       config.TTL,
+		detailMaxAge: config.DetailTTL,
+		retention:    detailRetention(config),
+		cleanupFreq:  config.CleanupFreq,
+		fs:           fs,
+	}
+}
+
+// withLock runs fn holding the cache lock, exclusive if the operation
+// writes. Reading a cache directory that does not exist needs no lock and
+// does not create it.
+//
+// The lock file lives on the real file system next to the cache data, so
+// this does not go through c.fs.
+func (c *FileCache) withLock(exclusive bool, fn func() error) error {
+	if exclusive {
+		if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+			return err
+		}
+	} else if !c.dirExists() {
+		return fn()
 	}
+
+	lock, err := acquireLock(c.lockPath, exclusive, c.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	return fn()
+}
+
+// dirExists reports whether the cache directory has been created
+func (c *FileCache) dirExists() bool {
+	_, err := os.Stat(c.cacheDir)
+	return err == nil
 }
 
 // GetGists retrieves cached gists
 func (c *FileCache) GetGists() ([]domain.Gist, error) {
+	var gists []domain.Gist
+	err := c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Missing, unreadable or legacy-format cache: treat as a miss
+			// and refetch.
+			return os.ErrNotExist
+		}
+		gists = payload.Gists
+		return nil
+	})
+	return gists, err
+}
+
+// SaveGists caches gists locally along with the ETag/Last-Modified
+// validators used to revalidate them
+func (c *FileCache) SaveGists(gists []domain.Gist, validators domain.CacheValidators) error {
+	return c.withLock(true, func() error {
+		now := time.Now()
+		return c.writePayload(cachePayload{FetchedAt: now, FullSyncAt: now, Validators: validators, Gists: gists})
+	})
+}
+
+// GetValidators returns the validators of the cached list. They are empty
+// when there is no usable cache.
+func (c *FileCache) GetValidators() domain.CacheValidators {
+	var validators domain.CacheValidators
+	_ = c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err == nil && ok {
+			validators = payload.Validators
+		}
+		return nil
+	})
+	return validators
+}
+
+// Touch resets the fetch time of the cached list after GitHub confirmed it
+// is unchanged
+func (c *FileCache) Touch() error {
+	return c.withLock(true, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return os.ErrNotExist
+		}
+
+		now := time.Now()
+		payload.FetchedAt, payload.FullSyncAt = now, now
+		return c.writePayload(payload)
+	})
+}
+
+// MergeGists adds or replaces gists fetched incrementally, placing them
+// first as the most recently updated, and advances the fetch time to
+// fetchedAt. Detail entries of merged gists are dropped as outdated.
+func (c *FileCache) MergeGists(gists []domain.Gist, fetchedAt time.Time) error {
+	return c.withLock(true, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return os.ErrNotExist
+		}
+
+		updated := make(map[domain.GistID]bool, len(gists))
+		for _, gist := range gists {
+			updated[gist.ID] = true
+			if err := c.removeDetail(gist.ID); err != nil {
+				return err
+			}
+		}
+
+		merged := append([]domain.Gist(nil), gists...)
+		for _, gist := range payload.Gists {
+			if !updated[gist.ID] {
+				merged = append(merged, gist)
+			}
+		}
+
+		payload.Gists = merged
+		payload.FetchedAt = fetchedAt
+		return c.writePayload(payload)
+	})
+}
+
+// GetSyncState reports when the cached list was last synced. It is zero when
+// there is no usable cache.
+func (c *FileCache) GetSyncState() domain.SyncState {
+	var state domain.SyncState
+	_ = c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err == nil && ok {
+			state = domain.SyncState{FetchedAt: payload.FetchedAt, FullSyncAt: payload.FullSyncAt}
+		}
+		return nil
+	})
+	return state
+}
+
+// PutGist adds or replaces a single gist in the cached list, keeping the
+// original fetch time, and drops its outdated detail cache entry. Without an
+// existing list cache there is nothing to amend; the next list fetch will
+// include the gist.
+func (c *FileCache) PutGist(gist domain.Gist) error {
+	return c.withLock(true, func() error {
+		if err := c.removeDetail(gist.ID); err != nil {
+			return err
+		}
+
+		payload, ok, err := c.readPayload()
+		if err != nil || !ok {
+			return err
+		}
+
+		replaced := false
+		for i := range payload.Gists {
+			if payload.Gists[i].ID == gist.ID {
+				payload.Gists[i] = gist
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			payload.Gists = append([]domain.Gist{gist}, payload.Gists...)
+		}
+
+		return c.writePayload(payload)
+	})
+}
+
+// RemoveGist drops a single gist from the cached list and the detail cache,
+// keeping the list's original fetch time so removing an entry does not make
+// the cache look fresher.
+func (c *FileCache) RemoveGist(id domain.GistID) error {
+	return c.withLock(true, func() error {
+		if err := c.removeDetail(id); err != nil {
+			return err
+		}
+
+		payload, ok, err := c.readPayload()
+		if err != nil || !ok {
+			return err
+		}
+
+		kept := payload.Gists[:0]
+		for _, g := range payload.Gists {
+			if g.ID != id {
+				kept = append(kept, g)
+			}
+		}
+		if len(kept) == len(payload.Gists) {
+			return nil
+		}
+		payload.Gists = kept
+
+		return c.writePayload(payload)
+	})
+}
+
+// GetGistDetail retrieves a fully fetched gist. An entry older than the
+// detail TTL is returned marked stale, for revalidation. An entry the cached
+// list shows a newer updated_at for is outdated and reported as a miss.
+func (c *FileCache) GetGistDetail(id domain.GistID) (*domain.CachedGist, error) {
+	var cached *domain.CachedGist
+	err := c.withLock(false, func() error {
+		detail, ok, err := c.readDetail(id)
+		if err != nil {
+			return err
+		}
+		if !ok || superseded(c.listedUpdates(), detail.Gist) {
+			return os.ErrNotExist
+		}
+
+		gist := detail.Gist
+		cached = &domain.CachedGist{
+			Gist:       &gist,
+			Validators: detail.Validators,
+			FetchedAt:  detail.FetchedAt,
+			Stale:      detail.FetchedAt.IsZero() || time.Since(detail.FetchedAt) > c.detailMaxAge,
+		}
+		return nil
+	})
+	return cached, err
+}
+
+// SaveGistDetail caches a fully fetched gist with its response validators
+func (c *FileCache) SaveGistDetail(gist domain.Gist, validators domain.CacheValidators) error {
+	path, ok := c.detailPath(gist.ID)
+	if !ok {
+		return domain.ErrInvalidGistID{ID: string(gist.ID)}
+	}
+
+	return c.withLock(true, func() error {
+		return c.writeDetail(path, detailPayload{FetchedAt: time.Now(), Validators: validators, Gist: gist})
+	})
+}
+
+// TouchGistDetail resets the fetch time of a cached gist after GitHub
+// confirmed it is unchanged
+func (c *FileCache) TouchGistDetail(id domain.GistID) error {
+	return c.withLock(true, func() error {
+		detail, ok, err := c.readDetail(id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return os.ErrNotExist
+		}
+
+		path, _ := c.detailPath(id)
+		detail.FetchedAt = time.Now()
+		return c.writeDetail(path, detail)
+	})
+}
+
+// detailPath returns the detail cache file of a gist. ok is false for IDs
+// that cannot be used as a file name.
+func (c *FileCache) detailPath(id domain.GistID) (string, bool) {
+	name := string(id)
+	if !id.Valid() || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return filepath.Join(c.detailDir, name+".json"), true
+}
+
+// removeDetail deletes the detail cache entry of a gist, if any
+func (c *FileCache) removeDetail(id domain.GistID) error {
+	path, ok := c.detailPath(id)
+	if !ok || !c.fs.Exists(path) {
+		return nil
+	}
+	return c.fs.RemoveAll(path)
+}
+
+// readDetail loads a detail cache entry. ok is false when there is no
+// usable entry.
+func (c *FileCache) readDetail(id domain.GistID) (detailPayload, bool, error) {
+	path, ok := c.detailPath(id)
+	if !ok || !c.fs.Exists(path) {
+		return detailPayload{}, false, nil
+	}
+
+	data, err := c.fs.ReadFile(path)
+	if err != nil {
+		return detailPayload{}, false, err
+	}
+
+	var detail detailPayload
+	if err := json.Unmarshal(data, &detail); err != nil || detail.Gist.ID != id {
+		// Unreadable entry will be refetched anyway
+		return detailPayload{}, false, nil
+	}
+
+	return detail, true, nil
+}
+
+// writeDetail persists a detail cache entry
+func (c *FileCache) writeDetail(path string, detail detailPayload) error {
+	data, err := json.MarshalIndent(detail, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return c.fs.WriteFile(path, data)
+}
+
+// listedUpdates maps the gists in the cached list to their updated_at. It
+// is read once per operation and checked with superseded.
+func (c *FileCache) listedUpdates() map[domain.GistID]time.Time {
+	payload, ok, err := c.readPayload()
+	if err != nil || !ok {
+		return nil
+	}
+
+	listed := make(map[domain.GistID]time.Time, len(payload.Gists))
+	for _, gist := range payload.Gists {
+		listed[gist.ID] = gist.UpdatedAt
+	}
+	return listed
+}
+
+// superseded reports whether the cached list holds a newer version of gist
+// than the detail cache
+func superseded(listed map[domain.GistID]time.Time, gist domain.Gist) bool {
+	updatedAt, ok := listed[gist.ID]
+	return ok && updatedAt.After(gist.UpdatedAt)
+}
+
+// readPayload loads the cached list. ok is false when there is no usable
+// cache (missing or unreadable), which callers treat as nothing to amend.
+func (c *FileCache) readPayload() (cachePayload, bool, error) {
 	if !c.fs.Exists(c.cacheFile) {
-		return nil, os.ErrNotExist
+		return cachePayload{}, false, nil
 	}
 
 	data, err := c.fs.ReadFile(c.cacheFile)
 	if err != nil {
-		return nil, err
+		return cachePayload{}, false, err
 	}
 
 	var payload cachePayload
 	if err := json.Unmarshal(data, &payload); err != nil {
-		// Unreadable or legacy-format cache: treat as a miss and refetch.
-		return nil, os.ErrNotExist
+		// Unreadable cache will be refetched anyway
+		return cachePayload{}, false, nil
 	}
 
-	return payload.Gists, nil
+	return payload, true, nil
 }
 
-// SaveGists caches gists locally
-func (c *FileCache) SaveGists(gists []domain.Gist) error {
-	// Ensure cache directory exists before writing
-	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
-		return err
-	}
-
-	payload := cachePayload{FetchedAt: time.Now(), Gists: gists}
+// writePayload persists the cached list as-is
+func (c *FileCache) writePayload(payload cachePayload) error {
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return err
@@ -86,29 +447,241 @@ func (c *FileCache) SaveGists(gists []domain.Gist) error {
 
 // IsStale checks if cache needs refreshing
 func (c *FileCache) IsStale() bool {
-	if !c.fs.Exists(c.cacheFile) {
-		return true
+	stale := true
+	_ = c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil || !ok || payload.FetchedAt.IsZero() {
+			return nil // no fetch timestamp (legacy cache) → force refresh
+		}
+		stale = time.Since(payload.FetchedAt) > c.maxAge
+		return nil
+	})
+	return stale
+}
+
+// Clear removes all cached data. The directory itself and its lock file are
+// kept, so another process waiting on the lock is not left holding a lock
+// on a deleted file.
+func (c *FileCache) Clear() error {
+	if !c.dirExists() {
+		return nil
 	}
 
-	data, err := c.fs.ReadFile(c.cacheFile)
+	return c.withLock(true, func() error {
+		if err := c.fs.RemoveAll(c.detailDir); err != nil {
+			return err
+		}
+
+		names, err := c.fs.ListFiles(c.cacheDir)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if name == lockFile {
+				continue
+			}
+			if err := c.fs.RemoveAll(filepath.Join(c.cacheDir, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecordLookups adds to the counts of gist lookups that were cache hits
+// and misses
+func (c *FileCache) RecordLookups(hits, misses int) error {
+	return c.withLock(true, func() error {
+		stats := c.readStats()
+		stats.Hits += hits
+		stats.Misses += misses
+		return c.writeStats(stats)
+	})
+}
+
+// ResetCounters restarts the lookup counters from now
+func (c *FileCache) ResetCounters() error {
+	return c.withLock(true, func() error {
+		return c.writeStats(statsPayload{Since: time.Now()})
+	})
+}
+
+// Stats summarizes the cached list, the detail cache and the lookup counters
+func (c *FileCache) Stats() (*domain.CacheStats, error) {
+	stats := &domain.CacheStats{Backend: domain.CacheBackendFile, Dir: c.cacheDir}
+	err := c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil {
+			return err
+		}
+		if ok {
+			stats.Listed = len(payload.Gists)
+			stats.FetchedAt, stats.FullSyncAt = payload.FetchedAt, payload.FullSyncAt
+			stats.Size += c.size(c.cacheFile)
+		}
+
+		names, err := c.detailNames()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			stats.Size += c.size(filepath.Join(c.detailDir, name))
+			detail, ok, err := c.readDetail(detailID(name))
+			if err != nil || !ok {
+				continue
+			}
+			stats.Details++
+			if stats.OldestDetail.IsZero() || detail.FetchedAt.Before(stats.OldestDetail) {
+				stats.OldestDetail = detail.FetchedAt
+			}
+		}
+
+		counters := c.readStats()
+		stats.Hits, stats.Misses, stats.CountingSince = counters.Hits, counters.Misses, counters.Since
+		return nil
+	})
 	if err != nil {
-		return true
+		return nil, err
 	}
+	return stats, nil
+}
 
-	var payload cachePayload
-	if err := json.Unmarshal(data, &payload); err != nil {
-		return true
+// Entries lists the gists of the cached list followed by the fully fetched
+// ones. A listed gist's size is that of its JSON in the list.
+func (c *FileCache) Entries() ([]domain.CacheEntry, error) {
+	var entries []domain.CacheEntry
+	err := c.withLock(false, func() error {
+		payload, ok, err := c.readPayload()
+		if err != nil {
+			return err
+		}
+		if ok {
+			for _, gist := range payload.Gists {
+				data, _ := json.Marshal(gist)
+				entries = append(entries, domain.CacheEntry{
+					ID:          gist.ID,
+					Description: gist.Description,
+					FetchedAt:   payload.FetchedAt,
+					Size:        int64(len(data)),
+				})
+			}
+		}
+
+		names, err := c.detailNames()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			detail, ok, err := c.readDetail(detailID(name))
+			if err != nil || !ok {
+				continue
+			}
+			entries = append(entries, domain.CacheEntry{
+				ID:          detail.Gist.ID,
+				Description: detail.Gist.Description,
+				Detail:      true,
+				FetchedAt:   detail.FetchedAt,
+				Size:        c.size(filepath.Join(c.detailDir, name)),
+			})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Prune removes the detail entries fetched before cutoff, and the cached
+// list if it was. Unreadable detail entries are removed too. It returns the
+// number of gists dropped from the list and the detail cache.
+func (c *FileCache) Prune(cutoff time.Time) (int, error) {
+	if !c.dirExists() {
+		return 0, nil
 	}
-	if payload.FetchedAt.IsZero() {
-		return true // no fetch timestamp (legacy cache) → force refresh
+
+	removed := 0
+	err := c.withLock(true, func() error {
+		names, err := c.detailNames()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			detail, ok, err := c.readDetail(detailID(name))
+			if err == nil && ok && !detail.FetchedAt.Before(cutoff) {
+				continue
+			}
+			if err := c.fs.RemoveAll(filepath.Join(c.detailDir, name)); err != nil {
+				return err
+			}
+			removed++
+		}
+
+		payload, ok, err := c.readPayload()
+		if err != nil || !ok || !payload.FetchedAt.Before(cutoff) {
+			return err
+		}
+		if err := c.fs.RemoveAll(c.cacheFile); err != nil {
+			return err
+		}
+		removed += len(payload.Gists)
+		return nil
+	})
+	return removed, err
+}
+
+// readStats loads the lookup counters. Missing or unreadable counters
+// start over from now.
+func (c *FileCache) readStats() statsPayload {
+	var stats statsPayload
+	if data, err := c.fs.ReadFile(c.statsFile); err == nil {
+		_ = json.Unmarshal(data, &stats)
+	}
+	if stats.Since.IsZero() {
+		stats = statsPayload{Since: time.Now()}
+	}
+	return stats
+}
+
+// writeStats persists the lookup counters
+func (c *FileCache) writeStats(stats statsPayload) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
 	}
 
-	return time.Since(payload.FetchedAt) > c.maxAge
+	return c.fs.WriteFile(c.statsFile, data)
 }
 
-// Clear removes all cached data
-func (c *FileCache) Clear() error {
-	return c.fs.RemoveAll(c.cacheDir)
+// size returns the size of a cache file, or 0 if it cannot be read
+func (c *FileCache) size(path string) int64 {
+	size, err := c.fs.Size(path)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// detailNames returns the file names of the detail cache entries, skipping
+// anything else in the directory, e.g. another process's in-flight write
+func (c *FileCache) detailNames() ([]string, error) {
+	names, err := c.fs.ListFiles(c.detailDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := names[:0]
+	for _, name := range names {
+		if strings.HasSuffix(name, ".json") {
+			entries = append(entries, name)
+		}
+	}
+	return entries, nil
+}
+
+// detailID returns the gist ID of a detail cache file name
+func detailID(name string) domain.GistID {
+	return domain.GistID(strings.TrimSuffix(name, ".json"))
 }
 
 // StartCleanup starts periodic cleanup of expired cache entries
@@ -122,7 +695,7 @@ func (c *FileCache) StartCleanup(ctx context.Context) {
 		for {
 			select {
 			case <-ticker.C:
-				if err := c.cleanup(); err != nil {
+				if err := c.lockedCleanup(); err != nil {
 					// Log error but don't crash
 					continue
 				}
@@ -133,8 +706,20 @@ func (c *FileCache) StartCleanup(ctx context.Context) {
 	}()
 }
 
+// lockedCleanup runs cleanup under the exclusive cache lock
+func (c *FileCache) lockedCleanup() error {
+	if !c.dirExists() {
+		return nil
+	}
+	return c.withLock(true, c.cleanup)
+}
+
 // cleanup removes expired cache entries
 func (c *FileCache) cleanup() error {
+	if err := c.cleanupDetails(); err != nil {
+		return err
+	}
+
 	entries, err := os.ReadDir(c.cacheDir)
 	if err != nil {
 		return err
@@ -145,8 +730,10 @@ func (c *FileCache) cleanup() error {
 			continue
 		}
 
-		// Skip the main gists.json file as it's managed separately
-		if strings.Contains(entry.Name(), "gists.json") {
+		// Skip the main gists.json file and the lookup counters as they're
+		// managed separately, and the lock file other processes may be
+		// waiting on
+		if strings.Contains(entry.Name(), "gists.json") || entry.Name() == filepath.Base(c.statsFile) || entry.Name() == lockFile {
 			continue
 		}
 
@@ -163,3 +750,28 @@ func (c *FileCache) cleanup() error {
 	}
 	return nil
 }
+
+// cleanupDetails removes detail cache entries that are past the detail
+// retention, unreadable, or outdated by the cached list. Entries that are only
+// stale are kept to be revalidated or read offline.
+func (c *FileCache) cleanupDetails() error {
+	names, err := c.detailNames()
+	if err != nil {
+		return err
+	}
+
+	listed := c.listedUpdates()
+	for _, name := range names {
+		detail, ok, err := c.readDetail(detailID(name))
+		if err != nil {
+			continue
+		}
+		if ok && time.Since(detail.FetchedAt) <= c.retention && !superseded(listed, detail.Gist) {
+			continue
+		}
+		if err := c.fs.RemoveAll(filepath.Join(c.detailDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// lockFile is the advisory lock file inside the cache directory
+const lockFile = ".lock"
+
+// lockTimeout bounds how long an operation waits for another process
+// holding the cache lock
+const lockTimeout = 5 * time.Second
+
+// lockRetry is the delay between attempts to take a held lock
+const lockRetry = 20 * time.Millisecond
+
+// fileLock is an advisory lock held on an open lock file. The lock belongs
+// to the open file rather than the process, so it also serializes separate
+// caches on the same directory within one process.
+type fileLock struct {
+	f *os.File
+}
+
+// acquireLock takes a shared or exclusive lock on path, creating the file if
+// needed. A conflicting lock is retried until timeout, after which
+// domain.ErrCacheLocked is returned.
+func acquireLock(path string, exclusive bool, timeout time.Duration) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLock(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return &fileLock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, domain.ErrCacheLocked{Path: filepath.Dir(path)}
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// release drops the lock and closes the lock file
+func (l *fileLock) release() error {
+	err := unlock(l.f)
+	if closeErr := l.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
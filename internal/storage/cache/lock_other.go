@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package cache
+
+import "os"
+
+// tryLock always succeeds: advisory locks are not available on this
+// platform, so the cache is shared unlocked
+func tryLock(f *os.File, exclusive bool) (bool, error) {
+	return true, nil
+}
+
+// unlock is a no-op without advisory locks
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+	"gist/internal/storage"
+)
+
+// lockedCacheDir returns a temp cache directory holding an empty list cache
+func lockedCacheDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	c := NewFileCache(dir, storage.NewOSFileSystem())
+	if err := c.SaveGists(nil, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	return dir
+}
+
+// assertCachedIDs checks that every expected gist survived in the list cache
+func assertCachedIDs(t *testing.T, dir string, want []domain.GistID) {
+	t.Helper()
+	gists, err := NewFileCache(dir, storage.NewOSFileSystem()).GetGists()
+	if err != nil {
+		t.Fatalf("GetGists: %v", err)
+	}
+
+	got := make(map[domain.GistID]bool, len(gists))
+	for _, g := range gists {
+		got[g.ID] = true
+	}
+	for _, id := range want {
+		if !got[id] {
+			t.Errorf("gist %s lost by a concurrent write", id)
+		}
+	}
+	if len(gists) != len(want) {
+		t.Errorf("expected %d cached gists, got %d", len(want), len(gists))
+	}
+}
+
+func TestFileCache_ConcurrentWriters(t *testing.T) {
+	dir := lockedCacheDir(t)
+
+	const writers, perWriter = 8, 10
+	var want []domain.GistID
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		for i := 0; i < perWriter; i++ {
+			want = append(want, domain.GistID(fmt.Sprintf("w%di%d", w, i)))
+		}
+
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// A cache per writer, as separate processes would have
+			c := NewFileCache(dir, storage.NewOSFileSystem())
+			for i := 0; i < perWriter; i++ {
+				if err := c.PutGist(domain.Gist{ID: domain.GistID(fmt.Sprintf("w%di%d", w, i))}); err != nil {
+					errs <- err
+				}
+				c.GetGists()
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("PutGist: %v", err)
+	}
+	assertCachedIDs(t, dir, want)
+}
+
+// TestFileCache_HelperWriter is run as a separate process by
+// TestFileCache_ConcurrentProcesses; it does nothing in a normal test run.
+func TestFileCache_HelperWriter(t *testing.T) {
+	dir := os.Getenv("GIST_CACHE_TEST_DIR")
+	if dir == "" {
+		t.Skip("helper process only")
+	}
+	writer := os.Getenv("GIST_CACHE_TEST_WRITER")
+	count, _ := strconv.Atoi(os.Getenv("GIST_CACHE_TEST_COUNT"))
+
+	c := NewFileCache(dir, storage.NewOSFileSystem())
+	for i := 0; i < count; i++ {
+		if err := c.PutGist(domain.Gist{ID: domain.GistID(fmt.Sprintf("p%si%d", writer, i))}); err != nil {
+			t.Fatalf("PutGist: %v", err)
+		}
+		if err := c.lockedCleanup(); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+	}
+}
+
+func TestFileCache_ConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	dir := lockedCacheDir(t)
+
+	const processes, perProcess = 4, 15
+	var want []domain.GistID
+	cmds := make([]*exec.Cmd, processes)
+	for p := range cmds {
+		for i := 0; i < perProcess; i++ {
+			want = append(want, domain.GistID(fmt.Sprintf("p%di%d", p, i)))
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFileCache_HelperWriter$")
+		cmd.Env = append(os.Environ(),
+			"GIST_CACHE_TEST_DIR="+dir,
+			"GIST_CACHE_TEST_WRITER="+strconv.Itoa(p),
+			"GIST_CACHE_TEST_COUNT="+strconv.Itoa(perProcess),
+		)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start writer: %v", err)
+		}
+		cmds[p] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("writer failed: %v", err)
+		}
+	}
+
+	assertCachedIDs(t, dir, want)
+}
+
+func TestFileCache_LockTimeout(t *testing.T) {
+	dir := lockedCacheDir(t)
+	c := NewFileCache(dir, storage.NewOSFileSystem())
+	c.lockTimeout = 50 * time.Millisecond
+
+	held, err := acquireLock(filepath.Join(dir, lockFile), true, time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+
+	var locked domain.ErrCacheLocked
+	if err := c.SaveGists(sampleGists(), domain.CacheValidators{}); !errors.As(err, &locked) {
+		t.Errorf("expected ErrCacheLocked writing, got %v", err)
+	}
+	if _, err := c.GetGists(); !errors.As(err, &locked) {
+		t.Errorf("expected ErrCacheLocked reading, got %v", err)
+	}
+	if !c.IsStale() {
+		t.Error("expected an unreadable cache to be stale")
+	}
+
+	if err := held.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := c.SaveGists(sampleGists(), domain.CacheValidators{}); err != nil {
+		t.Errorf("SaveGists after release: %v", err)
+	}
+}
+
+func TestBoltCache_MigrationTakesFileLock(t *testing.T) {
+	dir := lockedCacheDir(t)
+	fs := storage.NewOSFileSystem()
+	legacy := NewFileCache(dir, fs)
+	legacy.lockTimeout = 50 * time.Millisecond
+	if err := legacy.SaveGists(sampleGists(), domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGists: %v", err)
+	}
+	c := &BoltCache{cacheDir: dir, path: filepath.Join(dir, boltFile), fs: fs}
+
+	held, err := acquireLock(filepath.Join(dir, lockFile), true, time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+	var locked domain.ErrCacheLocked
+	if err := c.migrate(legacy); !errors.As(err, &locked) {
+		t.Errorf("expected the migration to wait for the file cache lock, got %v", err)
+	}
+	if c.exists() || !fs.Exists(legacy.cacheFile) {
+		t.Error("expected nothing migrated while another process holds the lock")
+	}
+
+	if err := held.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := c.migrate(legacy); err != nil {
+		t.Fatalf("migrate after release: %v", err)
+	}
+	if gists, err := c.GetGists(); err != nil || len(gists) != len(sampleGists()) {
+		t.Errorf("expected the JSON cache migrated, got %d gists, %v", len(gists), err)
+	}
+}
+
+func TestFileCache_SharedReaders(t *testing.T) {
+	dir := lockedCacheDir(t)
+	c := NewFileCache(dir, storage.NewOSFileSystem())
+	c.lockTimeout = 50 * time.Millisecond
+
+	held, err := acquireLock(filepath.Join(dir, lockFile), false, time.Second)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+	defer held.release()
+
+	if _, err := c.GetGists(); err != nil {
+		t.Errorf("expected readers to share the lock, got %v", err)
+	}
+}
+
+func TestFileCache_ClearKeepsLockFile(t *testing.T) {
+	dir := lockedCacheDir(t)
+	c := NewFileCache(dir, storage.NewOSFileSystem())
+	if err := c.SaveGistDetail(domain.Gist{ID: "abc"}, domain.CacheValidators{}); err != nil {
+		t.Fatalf("SaveGistDetail: %v", err)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != lockFile {
+		t.Errorf("expected only the lock file left, got %v", entries)
+	}
+	if _, err := c.GetGists(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a miss after Clear, got %v", err)
+	}
+}
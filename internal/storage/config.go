@@ -2,64 +2,321 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gist/internal/domain"
 	"gist/internal/service"
 )
 
 // ConfigFile implements the ConfigRepository interface using a JSON file
+// holding named profiles. A profile's token is kept in the file itself or,
+// with another token store, in an encrypted secrets file or a credential
+// helper.
 type ConfigFile struct {
-	fs         service.FileSystem
-	configPath string
+	fs          service.FileSystem
+	configPath  string
+	secretsPath string
+	passphrase  func() (string, error)
+	profile     string
 }
 
-// NewConfigFile creates a new file-based configuration repository
+// configData is the layout of the config file. Files written before
+// profiles existed hold a single flat github_user/github_token pair, which
+// is read as the default profile and rewritten as profiles on save.
+type configData struct {
+	CurrentProfile string                   `json:"current_profile,omitempty"`
+	Profiles       map[string]profileConfig `json:"profiles,omitempty"`
+
+	GitHubUser  string `json:"github_user,omitempty"`
+	GitHubToken string `json:"github_token,omitempty"`
+}
+
+// profileConfig is the credentials of one profile. GitHubToken is empty
+// unless the token is stored in plain text; APIURL is empty for github.com.
+type profileConfig struct {
+	GitHubUser       string `json:"github_user"`
+	GitHubToken      string `json:"github_token,omitempty"`
+	TokenStore       string `json:"token_store,omitempty"`
+	CredentialHelper string `json:"credential_helper,omitempty"`
+	APIURL           string `json:"api_url,omitempty"`
+}
+
+// NewConfigFile creates a new file-based configuration repository. The
+// passphrase of the encrypted secrets file is read from GIST_PASSPHRASE
+// unless SetPassphraseSource provides another source.
 func NewConfigFile(fs service.FileSystem) *ConfigFile {
 	home, _ := os.UserHomeDir()
 	return &ConfigFile{
-		fs:         fs,
-		configPath: filepath.Join(home, ".gistconfig"),
+		fs:          fs,
+		configPath:  filepath.Join(home, ".gistconfig"),
+		secretsPath: filepath.Join(home, ".gist-secrets"),
+		passphrase:  EnvPassphrase,
 	}
 }
 
-// Load retrieves configuration from file
-func (c *ConfigFile) Load() (*domain.Config, error) {
-	if !c.fs.Exists(c.configPath) {
-		return nil, os.ErrNotExist
-	}
+// SetPassphraseSource sets how the secrets file passphrase is obtained
+func (c *ConfigFile) SetPassphraseSource(passphrase func() (string, error)) {
+	c.passphrase = passphrase
+}
+
+// SetProfile selects the profile Load and Save use. Empty selects the
+// file's current profile.
+func (c *ConfigFile) SetProfile(name string) {
+	c.profile = name
+}
 
-	data, err := c.fs.ReadFile(c.configPath)
+// Load retrieves the selected profile from file
+func (c *ConfigFile) Load() (*domain.Config, error) {
+	data, err := c.read()
 	if err != nil {
 		return nil, err
 	}
 
-	var configMap map[string]string
-	if err := json.Unmarshal(data, &configMap); err != nil {
+	name := c.profile
+	if name == "" {
+		name = data.currentProfile()
+	}
+	if !domain.ValidProfileName(name) {
+		return nil, domain.ErrInvalidProfile{Name: name}
+	}
+
+	profile, ok := data.Profiles[name]
+	if !ok {
+		if c.profile == "" {
+			return nil, os.ErrNotExist
+		}
+		return nil, domain.ErrProfileNotFound{Name: name}
+	}
+
+	config := domain.NewConfig(profile.GitHubUser, profile.GitHubToken)
+	config.Profile = name
+	config.TokenStore = profile.TokenStore
+	config.CredentialHelper = profile.CredentialHelper
+	config.APIURL = profile.APIURL
+
+	store, err := c.secretStore(profile.TokenStore, profile.CredentialHelper)
+	if err != nil {
 		return nil, err
 	}
+	if store != nil {
+		token, err := store.Get(domain.WebHost(profile.APIURL), profile.GitHubUser)
+		if err != nil {
+			return nil, fmt.Errorf("read token of profile %s: %w", name, err)
+		}
+		config.GitHubToken = token
+	}
 
-	config := domain.NewConfig(configMap["github_user"], configMap["github_token"])
 	return config, nil
 }
 
-// Save persists configuration to file
+// Save persists configuration to file as config.Profile, or the selected
+// profile, keeping the other profiles. The token goes to the profile's
+// token store. The first profile saved becomes the current one.
 func (c *ConfigFile) Save(config *domain.Config) error {
-	configMap := map[string]string{
-		"github_user":  config.GitHubUser,
-		"github_token": config.GitHubToken,
+	data, err := c.read()
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = &configData{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	name := config.Profile
+	if name == "" {
+		name = c.profile
+	}
+	if name == "" {
+		name = domain.DefaultProfile
+	}
+	if !domain.ValidProfileName(name) {
+		return domain.ErrInvalidProfile{Name: name}
+	}
+
+	apiURL, err := domain.NormalizeAPIURL(config.APIURL)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(configMap, "", "  ")
+	profile := profileConfig{
+		GitHubUser:       config.GitHubUser,
+		TokenStore:       config.TokenStore,
+		CredentialHelper: config.CredentialHelper,
+		APIURL:           apiURL,
+	}
+	store, err := c.secretStore(config.TokenStore, config.CredentialHelper)
 	if err != nil {
 		return err
 	}
+	if store == nil {
+		profile.GitHubToken = config.GitHubToken
+	} else if err := store.Set(domain.WebHost(apiURL), config.GitHubUser, config.GitHubToken); err != nil {
+		return fmt.Errorf("store token of profile %s: %w", name, err)
+	}
+
+	if len(data.Profiles) == 0 {
+		data.Profiles = make(map[string]profileConfig)
+		data.CurrentProfile = name
+	}
+	data.Profiles[name] = profile
 
-	return c.fs.WriteFile(c.configPath, data)
+	return c.write(data)
 }
 
-// GetFromEnv loads config from environment variables
+// ListProfiles returns the profiles in the config file, sorted by name
+func (c *ConfigFile) ListProfiles() ([]domain.Profile, error) {
+	data, err := c.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current := data.currentProfile()
+	profiles := make([]domain.Profile, 0, len(data.Profiles))
+	for name, profile := range data.Profiles {
+		store := profile.TokenStore
+		if store == "" {
+			store = domain.TokenStorePlain
+		}
+		profiles = append(profiles, domain.Profile{
+			Name:       name,
+			GitHubUser: profile.GitHubUser,
+			TokenStore: store,
+			Current:    name == current,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	return profiles, nil
+}
+
+// UseProfile makes an existing profile the current one
+func (c *ConfigFile) UseProfile(name string) error {
+	data, err := c.profileData(name)
+	if err != nil {
+		return err
+	}
+
+	data.CurrentProfile = name
+	return c.write(data)
+}
+
+// RemoveProfile deletes a profile and the token it keeps in a secret
+// store. Removing the current profile leaves no profile current, so the
+// default one is used.
+func (c *ConfigFile) RemoveProfile(name string) error {
+	data, err := c.profileData(name)
+	if err != nil {
+		return err
+	}
+
+	profile := data.Profiles[name]
+	delete(data.Profiles, name)
+	if data.CurrentProfile == name {
+		data.CurrentProfile = ""
+	}
+	if err := c.write(data); err != nil {
+		return err
+	}
+
+	store, err := c.secretStore(profile.TokenStore, profile.CredentialHelper)
+	if err != nil || store == nil {
+		return err
+	}
+	if err := store.Delete(domain.WebHost(profile.APIURL), profile.GitHubUser); err != nil {
+		return fmt.Errorf("remove token of profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// secretStore opens the store a profile keeps its token in. It is nil for
+// the plain store, which keeps the token in the config file.
+func (c *ConfigFile) secretStore(store, helper string) (service.SecretStore, error) {
+	switch store {
+	case "", domain.TokenStorePlain:
+		return nil, nil
+	case domain.TokenStoreFile:
+		return NewEncryptedFileStore(c.fs, c.secretsPath, c.passphrase), nil
+	case domain.TokenStoreHelper:
+		return NewCredentialHelper(helper), nil
+	default:
+		return nil, domain.ErrUnknownTokenStore{Store: store}
+	}
+}
+
+// profileData reads the config file and checks that it holds profile name
+func (c *ConfigFile) profileData(name string) (*configData, error) {
+	if !domain.ValidProfileName(name) {
+		return nil, domain.ErrInvalidProfile{Name: name}
+	}
+
+	data, err := c.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrProfileNotFound{Name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := data.Profiles[name]; !ok {
+		return nil, domain.ErrProfileNotFound{Name: name}
+	}
+
+	return data, nil
+}
+
+// read loads the config file, converting the flat legacy format into a
+// default profile
+func (c *ConfigFile) read() (*configData, error) {
+	if !c.fs.Exists(c.configPath) {
+		return nil, os.ErrNotExist
+	}
+
+	raw, err := c.fs.ReadFile(c.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var data configData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", c.configPath, err)
+	}
+
+	if len(data.Profiles) == 0 && (data.GitHubUser != "" || data.GitHubToken != "") {
+		data.Profiles = map[string]profileConfig{
+			domain.DefaultProfile: {GitHubUser: data.GitHubUser, GitHubToken: data.GitHubToken},
+		}
+		data.CurrentProfile = domain.DefaultProfile
+	}
+	data.GitHubUser, data.GitHubToken = "", ""
+
+	return &data, nil
+}
+
+// write persists the config file in the profiles format
+func (c *ConfigFile) write(data *configData) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return c.fs.WriteFile(c.configPath, raw)
+}
+
+// currentProfile returns the profile used when none is selected
+func (d *configData) currentProfile() string {
+	if d.CurrentProfile != "" {
+		return d.CurrentProfile
+	}
+	return domain.DefaultProfile
+}
+
+// GetFromEnv loads config from environment variables. GIST_API_URL goes
+// with the environment's token only; profiles keep their own API URL.
 func (c *ConfigFile) GetFromEnv() (*domain.Config, error) {
 	// Try different token names
 	token := os.Getenv("GITHUB_TOKEN")
@@ -73,22 +330,65 @@ func (c *ConfigFile) GetFromEnv() (*domain.Config, error) {
 		return nil, domain.ErrConfigMissing{Field: "GITHUB_USER or GITHUB_TOKEN"}
 	}
 
+	apiURL, err := domain.NormalizeAPIURL(os.Getenv("GIST_API_URL"))
+	if err != nil {
+		return nil, fmt.Errorf("GIST_API_URL: %w", err)
+	}
+	config.APIURL = apiURL
+
 	return config, nil
 }
 
-// LoadConfig attempts to load configuration from multiple sources
-func LoadConfig(fs service.FileSystem) (*domain.Config, error) {
-	configRepo := NewConfigFile(fs)
+// LoadConfig attempts to load configuration from multiple sources. A
+// selected profile is read from the config file only, so credentials in
+// the environment cannot shadow it; otherwise the environment comes first,
+// then the config file's current profile. Tokens kept in a secret store are
+// resolved through it.
+func LoadConfig(configRepo *ConfigFile, profile string) (*domain.Config, error) {
+	if profile != "" {
+		configRepo.SetProfile(profile)
+		config, err := configRepo.Load()
+		if err != nil {
+			return nil, err
+		}
+		if !config.Valid() {
+			return nil, domain.ErrConfigMissing{Field: "github credentials of profile " + profile}
+		}
+		return config, nil
+	}
 
 	// Try environment variables first
-	if config, err := configRepo.GetFromEnv(); err == nil {
+	config, err := configRepo.GetFromEnv()
+	if err == nil {
 		return config, nil
 	}
+	var missing domain.ErrConfigMissing
+	if !errors.As(err, &missing) {
+		return nil, err
+	}
 
 	// Try config file
-	if config, err := configRepo.Load(); err == nil && config.Valid() {
+	config, err = configRepo.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil && config.Valid() {
 		return config, nil
 	}
 
 	return nil, domain.ErrConfigMissing{Field: "github credentials"}
 }
+
+// CacheBaseDir returns the directory holding the caches of all profiles:
+// config.Dir when set, else ~/.gist-cache
+func CacheBaseDir(config domain.CacheConfig) (string, error) {
+	if config.Dir != "" {
+		return config.Dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".gist-cache"), nil
+}
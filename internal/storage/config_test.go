@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func newTestConfigFile(t *testing.T, content string) *ConfigFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".gistconfig")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	return &ConfigFile{fs: NewOSFileSystem(), configPath: path}
+}
+
+func TestConfigFile_ReadsLegacyFlatFormat(t *testing.T) {
+	c := newTestConfigFile(t, `{"github_user": "me", "github_token": "tok"}`)
+
+	config, err := c.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.GitHubUser != "me" || config.GitHubToken != "tok" || config.Profile != domain.DefaultProfile {
+		t.Errorf("expected the flat file as the default profile, got %+v", config)
+	}
+
+	// Saving another profile keeps the legacy credentials as default
+	team := domain.NewConfig("team-bot", "team-tok")
+	team.Profile = "team"
+	if err := c.Save(team); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	raw, _ := os.ReadFile(c.configPath)
+	if !strings.Contains(string(raw), `"profiles"`) {
+		t.Errorf("expected the file rewritten with profiles, got %s", raw)
+	}
+
+	profiles, err := c.ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	want := []domain.Profile{
+		{Name: "default", GitHubUser: "me", TokenStore: domain.TokenStorePlain, Current: true},
+		{Name: "team", GitHubUser: "team-bot", TokenStore: domain.TokenStorePlain},
+	}
+	if len(profiles) != len(want) || profiles[0] != want[0] || profiles[1] != want[1] {
+		t.Errorf("ListProfiles = %+v, want %+v", profiles, want)
+	}
+}
+
+func TestConfigFile_SelectUseRemove(t *testing.T) {
+	c := newTestConfigFile(t, "")
+
+	for _, name := range []string{"personal", "team"} {
+		config := domain.NewConfig(name+"-user", name+"-tok")
+		config.Profile = name
+		if err := c.Save(config); err != nil {
+			t.Fatalf("Save %s: %v", name, err)
+		}
+	}
+
+	// The first profile saved becomes current
+	if config, err := c.Load(); err != nil || config.Profile != "personal" {
+		t.Fatalf("expected personal as current, got %+v err=%v", config, err)
+	}
+
+	c.SetProfile("team")
+	if config, err := c.Load(); err != nil || config.GitHubUser != "team-user" {
+		t.Errorf("expected the selected team profile, got %+v err=%v", config, err)
+	}
+	c.SetProfile("")
+
+	if err := c.UseProfile("team"); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+	if config, err := c.Load(); err != nil || config.Profile != "team" {
+		t.Errorf("expected team as current, got %+v err=%v", config, err)
+	}
+
+	var notFound domain.ErrProfileNotFound
+	if err := c.UseProfile("missing"); !errors.As(err, &notFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+	var invalid domain.ErrInvalidProfile
+	if err := c.UseProfile("../etc"); !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidProfile, got %v", err)
+	}
+
+	if err := c.RemoveProfile("team"); err != nil {
+		t.Fatalf("RemoveProfile: %v", err)
+	}
+	c.SetProfile("team")
+	if _, err := c.Load(); !errors.As(err, &notFound) {
+		t.Errorf("expected the removed profile to be gone, got %v", err)
+	}
+	c.SetProfile("")
+	if _, err := c.Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no default profile after removing the current one, got %v", err)
+	}
+}
+
+func TestLoadConfig_SelectedProfileIgnoresEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GITHUB_USER", "env-user")
+	t.Setenv("GITHUB_TOKEN", "env-tok")
+
+	c := NewConfigFile(NewOSFileSystem())
+	team := domain.NewConfig("team-user", "team-tok")
+	team.Profile = "team"
+	if err := c.Save(team); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	config, err := LoadConfig(NewConfigFile(NewOSFileSystem()), "")
+	if err != nil || config.GitHubUser != "env-user" || config.Profile != "" {
+		t.Errorf("expected env credentials without a selected profile, got %+v err=%v", config, err)
+	}
+
+	config, err = LoadConfig(NewConfigFile(NewOSFileSystem()), "team")
+	if err != nil || config.GitHubUser != "team-user" || config.Profile != "team" {
+		t.Errorf("expected the team profile, got %+v err=%v", config, err)
+	}
+
+	var notFound domain.ErrProfileNotFound
+	if _, err := LoadConfig(NewConfigFile(NewOSFileSystem()), "other"); !errors.As(err, &notFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestConfigFile_TokenInEncryptedFile(t *testing.T) {
+	c := newTestConfigFile(t, "")
+	c.secretsPath = filepath.Join(t.TempDir(), ".gist-secrets")
+	c.passphrase = func() (string, error) { return "hunter2", nil }
+
+	config := domain.NewConfig("me", "secret-tok")
+	config.TokenStore = domain.TokenStoreFile
+	if err := c.Save(config); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	raw, _ := os.ReadFile(c.configPath)
+	if strings.Contains(string(raw), "secret-tok") {
+		t.Errorf("expected no token in the config file, got %s", raw)
+	}
+
+	loaded, err := c.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.GitHubToken != "secret-tok" || loaded.TokenStore != domain.TokenStoreFile {
+		t.Errorf("expected the token resolved from the secrets file, got %+v", loaded)
+	}
+
+	c.passphrase = func() (string, error) { return "wrong", nil }
+	if _, err := c.Load(); err == nil {
+		t.Error("expected an error loading with the wrong passphrase")
+	}
+}
+
+func TestConfigFile_APIURL(t *testing.T) {
+	c := newTestConfigFile(t, "")
+	c.secretsPath = filepath.Join(t.TempDir(), ".gist-secrets")
+	c.passphrase = func() (string, error) { return "hunter2", nil }
+
+	config := domain.NewConfig("me", "ghe-tok")
+	config.Profile = "work"
+	config.TokenStore = domain.TokenStoreFile
+	config.APIURL = "https://github.example.com/"
+	if err := c.Save(config); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	c.SetProfile("work")
+	loaded, err := c.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.APIURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected the normalized Enterprise API URL, got %q", loaded.APIURL)
+	}
+
+	// The token is kept for the Enterprise host, not github.com
+	store := NewEncryptedFileStore(c.fs, c.secretsPath, c.passphrase)
+	if token, err := store.Get("github.example.com", "me"); err != nil || token != "ghe-tok" {
+		t.Errorf("expected the token stored for github.example.com, got %q, %v", token, err)
+	}
+
+	bad := domain.NewConfig("me", "tok")
+	bad.APIURL = "ftp://github.example.com"
+	var invalid domain.ErrInvalidAPIURL
+	if err := c.Save(bad); !errors.As(err, &invalid) {
+		t.Errorf("expected ErrInvalidAPIURL, got %v", err)
+	}
+}
+
+func TestGetFromEnv_APIURL(t *testing.T) {
+	t.Setenv("GITHUB_USER", "env-user")
+	t.Setenv("GITHUB_TOKEN", "env-tok")
+	c := newTestConfigFile(t, "")
+
+	t.Setenv("GIST_API_URL", "")
+	if config, err := c.GetFromEnv(); err != nil || config.APIURL != "" {
+		t.Errorf("expected github.com without GIST_API_URL, got %+v err=%v", config, err)
+	}
+
+	t.Setenv("GIST_API_URL", "https://api.github.com/")
+	if config, err := c.GetFromEnv(); err != nil || config.APIURL != "" {
+		t.Errorf("expected the github.com API URL normalized to empty, got %+v err=%v", config, err)
+	}
+
+	t.Setenv("GIST_API_URL", "https://ghe.internal/api/v3")
+	if config, err := c.GetFromEnv(); err != nil || config.APIURL != "https://ghe.internal/api/v3" {
+		t.Errorf("expected GIST_API_URL, got %+v err=%v", config, err)
+	}
+
+	t.Setenv("GIST_API_URL", "ghe.internal")
+	var invalid domain.ErrInvalidAPIURL
+	if _, err := LoadConfig(c, ""); !errors.As(err, &invalid) {
+		t.Errorf("expected LoadConfig to report the invalid GIST_API_URL, got %v", err)
+	}
+}
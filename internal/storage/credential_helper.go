@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gist/internal/domain"
+)
+
+// CredentialHelper implements the SecretStore interface by speaking the
+// git credential helper protocol, so tokens can live in the OS keyring
+// through helpers such as git-credential-osxkeychain, git-credential-libsecret
+// or Git Credential Manager.
+//
+// The command follows git's credential.helper rules: "!cmd" is a shell
+// command, an absolute path runs as given, and anything else is prefixed
+// with "git credential-", so "cache --timeout=300" runs
+// "git credential-cache --timeout=300". The action (get, store or erase) is
+// appended to the command, and the credential is exchanged as key=value
+// lines on stdin and stdout.
+type CredentialHelper struct {
+	command string
+	shell   bool
+}
+
+// NewCredentialHelper creates a secret store backed by a credential helper
+func NewCredentialHelper(command string) *CredentialHelper {
+	switch {
+	case command == "":
+	case strings.HasPrefix(command, "!"):
+		return &CredentialHelper{command: command[1:], shell: true}
+	case !filepath.IsAbs(command):
+		command = "git credential-" + command
+	}
+	return &CredentialHelper{command: command}
+}
+
+// Get returns the token of user on host
+func (h *CredentialHelper) Get(host, user string) (string, error) {
+	out, err := h.run("get", credentialAttrs(host, user, ""))
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if token, ok := strings.CutPrefix(scanner.Text(), "password="); ok && token != "" {
+			return token, nil
+		}
+	}
+	return "", domain.ErrSecretNotFound{Key: secretKey(host, user)}
+}
+
+// Set stores the token of user on host
+func (h *CredentialHelper) Set(host, user, token string) error {
+	_, err := h.run("store", credentialAttrs(host, user, token))
+	return err
+}
+
+// Delete removes the token of user on host
+func (h *CredentialHelper) Delete(host, user string) error {
+	_, err := h.run("erase", credentialAttrs(host, user, ""))
+	return err
+}
+
+// run invokes the helper with action, feeding it input
+func (h *CredentialHelper) run(action, input string) ([]byte, error) {
+	if h.command == "" {
+		return nil, domain.ErrConfigMissing{Field: "credential helper command"}
+	}
+
+	cmd := h.commandFor(action)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %s: %w: %s", action, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %s: %w", action, err)
+	}
+	return out, nil
+}
+
+// commandFor builds the helper invocation for action. Helpers run through
+// sh as git runs them; Windows has no sh, so there a shell command goes
+// through cmd and any other helper is split on spaces and run directly.
+func (h *CredentialHelper) commandFor(action string) *exec.Cmd {
+	if runtime.GOOS != "windows" {
+		return exec.Command("sh", "-c", h.command+" "+action)
+	}
+	if h.shell {
+		return exec.Command("cmd", "/C", h.command+" "+action)
+	}
+	args := append(strings.Fields(h.command), action)
+	return exec.Command(args[0], args[1:]...)
+}
+
+// credentialAttrs formats a credential description for a helper
+func credentialAttrs(host, user, token string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "protocol=https\nhost=%s\nusername=%s\n", host, user)
+	if token != "" {
+		fmt.Fprintf(&b, "password=%s\n", token)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
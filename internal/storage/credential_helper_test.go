@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+// fakeHelper is a credential helper script keeping user=password lines in a
+// file next to it
+const fakeHelper = `#!/bin/sh
+store="$(dirname "$0")/store"
+touch "$store"
+while IFS= read -r line && [ -n "$line" ]; do
+	case "$line" in
+	username=*) user="${line#username=}" ;;
+	password=*) pass="${line#password=}" ;;
+	esac
+done
+case "$1" in
+get) grep "^$user=" "$store" | sed "s/^$user=/password=/" ;;
+store) grep -v "^$user=" "$store" > "$store.tmp"; echo "$user=$pass" >> "$store.tmp"; mv "$store.tmp" "$store" ;;
+erase) grep -v "^$user=" "$store" > "$store.tmp"; mv "$store.tmp" "$store" ;;
+esac
+exit 0
+`
+
+func newFakeHelper(t *testing.T) (*CredentialHelper, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helper is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(script, []byte(fakeHelper), 0700); err != nil {
+		t.Fatalf("write helper: %v", err)
+	}
+	return NewCredentialHelper(script), filepath.Join(dir, "store")
+}
+
+func TestNewCredentialHelper_GitRules(t *testing.T) {
+	tests := []struct {
+		helper string
+		want   string
+		shell  bool
+	}{
+		{"osxkeychain", "git credential-osxkeychain", false},
+		{"cache --timeout=300", "git credential-cache --timeout=300", false},
+		{"/usr/bin/helper --flag", "/usr/bin/helper --flag", false},
+		{"!pass-helper --store ~/.tokens", "pass-helper --store ~/.tokens", true},
+	}
+	for _, tt := range tests {
+		h := NewCredentialHelper(tt.helper)
+		if h.command != tt.want || h.shell != tt.shell {
+			t.Errorf("NewCredentialHelper(%q) = %q shell=%v, want %q shell=%v", tt.helper, h.command, h.shell, tt.want, tt.shell)
+		}
+	}
+}
+
+func TestCredentialHelper_NameWithArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake git is a shell script")
+	}
+
+	// A fake git records how it was invoked
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + args + "\ncat > /dev/null\n"
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0700); err != nil {
+		t.Fatalf("write git: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if err := NewCredentialHelper("cache --timeout=300").Set("github.com", "me", "tok"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if raw, _ := os.ReadFile(args); strings.TrimSpace(string(raw)) != "credential-cache --timeout=300 store" {
+		t.Errorf("git invoked with %q, want credential-cache --timeout=300 store", raw)
+	}
+}
+
+func TestCredentialHelper_ShellFormWithArguments(t *testing.T) {
+	h, store := newFakeHelper(t)
+
+	// A shell helper gets its own arguments before the action
+	shell := NewCredentialHelper("!f() { shift; exec " + h.command + " \"$@\"; }; f --ignored")
+	if err := shell.Set("github.com", "me", "tok-me"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if token, err := shell.Get("github.com", "me"); err != nil || token != "tok-me" {
+		t.Errorf("Get = %q, %v; want tok-me", token, err)
+	}
+	if raw, _ := os.ReadFile(store); !strings.Contains(string(raw), "me=tok-me") {
+		t.Errorf("expected the helper to receive the credential, got %q", raw)
+	}
+}
+
+func TestCredentialHelper_RoundTrip(t *testing.T) {
+	h, store := newFakeHelper(t)
+
+	if err := h.Set("github.com", "me", "tok-me"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	raw, _ := os.ReadFile(store)
+	if !strings.Contains(string(raw), "me=tok-me") {
+		t.Errorf("expected the helper to receive the credential, got %q", raw)
+	}
+
+	token, err := h.Get("github.com", "me")
+	if err != nil || token != "tok-me" {
+		t.Errorf("Get = %q, %v; want tok-me", token, err)
+	}
+
+	if err := h.Delete("github.com", "me"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	var notFound domain.ErrSecretNotFound
+	if _, err := h.Get("github.com", "me"); !errors.As(err, &notFound) {
+		t.Errorf("expected ErrSecretNotFound after Delete, got %v", err)
+	}
+}
+
+func TestCredentialHelper_Failure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helpers run through sh")
+	}
+
+	h := NewCredentialHelper("!echo broken >&2; exit 1; true")
+	if _, err := h.Get("github.com", "me"); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the helper's stderr in the error, got %v", err)
+	}
+}
+
+func TestConfigFile_TokenInCredentialHelper(t *testing.T) {
+	h, store := newFakeHelper(t)
+	c := newTestConfigFile(t, "")
+
+	config := domain.NewConfig("me", "helper-tok")
+	config.TokenStore = domain.TokenStoreHelper
+	config.CredentialHelper = h.command
+	if err := c.Save(config); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	raw, _ := os.ReadFile(c.configPath)
+	if strings.Contains(string(raw), "helper-tok") {
+		t.Errorf("expected no token in the config file, got %s", raw)
+	}
+
+	loaded, err := c.Load()
+	if err != nil || loaded.GitHubToken != "helper-tok" {
+		t.Fatalf("expected the token resolved through the helper, got %+v err=%v", loaded, err)
+	}
+
+	if err := c.RemoveProfile(domain.DefaultProfile); err != nil {
+		t.Fatalf("RemoveProfile: %v", err)
+	}
+	if raw, _ := os.ReadFile(store); strings.Contains(string(raw), "helper-tok") {
+		t.Errorf("expected the token erased with the profile, got %q", raw)
+	}
+}
@@ -70,6 +70,22 @@ func (fs *OSFileSystem) WriteFile(path string, content []byte) error {
 	return nil
 }
 
+// ListFiles returns the names of the regular files in a directory
+func (fs *OSFileSystem) ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
 // RemoveAll removes a directory and all contents
 func (fs *OSFileSystem) RemoveAll(path string) error {
 	return os.RemoveAll(path)
@@ -121,3 +121,18 @@ func TestOSFileSystem_Exists_RemoveAll(t *testing.T) {
 		t.Error("should be removed")
 	}
 }
+
+func TestOSFileSystem_ListFiles(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewOSFileSystem()
+	_ = fs.WriteFile(filepath.Join(dir, "a"), []byte("x"))
+	_ = fs.WriteFile(filepath.Join(dir, "sub", "b"), []byte("x"))
+
+	names, err := fs.ListFiles(dir)
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(names) != 1 || names[0] != "a" {
+		t.Errorf("expected only the regular file 'a', got %v", names)
+	}
+}
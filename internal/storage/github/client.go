@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"gist/internal/domain"
@@ -22,23 +25,55 @@ type Client struct {
 	rateLimit  rateLimitState
 	retryMax   int
 	retryWait  time.Duration
+	perPage    int
+	offline    bool
 }
 
 type rateLimitState struct {
 	remaining int
+	limit     int
 	reset     time.Time
 }
 
+// defaultPerPage is the page size requested when listing gists. GitHub
+// allows at most 100 items per page.
+const defaultPerPage = 100
+
+// maxPerPage is the largest page size GitHub accepts for list endpoints.
+const maxPerPage = 100
+
 // NewClient creates a new GitHub API client
 func NewClient(token, username string) *Client {
 	return &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
-		baseURL:    "https://api.github.com",
+		baseURL:    domain.DefaultAPIURL,
 		token:      token,
 		username:   username,
 		retryMax:   3,
 		retryWait:  100 * time.Millisecond,
+		perPage:    defaultPerPage,
+	}
+}
+
+// SetPerPage sets the page size used when listing gists. Values outside
+// 1..100 fall back to the default.
+func (c *Client) SetPerPage(n int) {
+	if n <= 0 || n > maxPerPage {
+		n = defaultPerPage
 	}
+	c.perPage = n
+}
+
+// SetBaseURL points the client at another GitHub API, such as the
+// /api/v3 endpoint of GitHub Enterprise Server. Empty selects github.com.
+func (c *Client) SetBaseURL(apiURL string) {
+	c.baseURL = strings.TrimSuffix(domain.APIURLOrDefault(apiURL), "/")
+}
+
+// SetOffline makes every request fail with domain.ErrOffline without
+// touching the network
+func (c *Client) SetOffline(offline bool) {
+	c.offline = offline
 }
 
 // parseRateLimit extracts rate limit information from response headers
@@ -46,6 +81,9 @@ func (c *Client) parseRateLimit(resp *http.Response) {
 	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
 		c.rateLimit.remaining, _ = strconv.Atoi(remaining)
 	}
+	if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "" {
+		c.rateLimit.limit, _ = strconv.Atoi(limit)
+	}
 	if reset := resp.Header.Get("X-RateLimit-Reset"); reset != "" {
 		unix, _ := strconv.ParseInt(reset, 10, 64)
 		c.rateLimit.reset = time.Unix(unix, 0)
@@ -78,6 +116,17 @@ func (c *Client) rateLimitWait(resp *http.Response) time.Duration {
 
 // apiRequest makes an authenticated request to the GitHub API with rate limiting and retry logic
 func (c *Client) apiRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	return c.conditionalRequest(ctx, method, url, body, domain.CacheValidators{})
+}
+
+// conditionalRequest is apiRequest with If-None-Match / If-Modified-Since
+// headers set from validators. A 304 Not Modified response is returned to the
+// caller like any other non-error status.
+func (c *Client) conditionalRequest(ctx context.Context, method, url string, body []byte, validators domain.CacheValidators) (*http.Response, error) {
+	if c.offline {
+		return nil, domain.ErrOffline{}
+	}
+
 	var lastErr error
 	for attempt := 0; attempt <= c.retryMax; attempt++ {
 		var reader io.Reader
@@ -95,10 +144,16 @@ func (c *Client) apiRequest(ctx context.Context, method, url string, body []byte
 		if body != nil {
 			req.Header.Set("Content-Type", "application/json")
 		}
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			return nil, err
+			return nil, c.transportError(ctx, err)
 		}
 
 		c.parseRateLimit(resp)
@@ -146,10 +201,59 @@ func (c *Client) apiRequest(ctx context.Context, method, url string, body []byte
 	return nil, lastErr
 }
 
-// GetAll retrieves all gists for the authenticated user (both public and private)
+// transportError classifies a failed round trip. Cancellation by the caller
+// is returned as-is; anything else means GitHub could not be reached.
+func (c *Client) transportError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return domain.ErrNetwork{Err: err}
+}
+
+// GetAll retrieves all gists for the authenticated user (both public and
+// private), following Link rel="next" headers until every page is fetched.
+// If a page after the first fails, the gists fetched so far are returned
+// together with a domain.ErrPartialFetch.
 func (c *Client) GetAll(ctx context.Context) ([]domain.Gist, error) {
+	gists, _, err := c.GetAllIfModified(ctx, domain.CacheValidators{})
+	return gists, err
+}
+
+// GetAllIfModified is GetAll as a conditional request. When the listing still
+// matches validators it returns domain.ErrNotModified without downloading it,
+// which GitHub does not count against the rate limit. The returned validators
+// are empty for multi-page listings: a 304 for the first page says nothing
+// about the others.
+func (c *Client) GetAllIfModified(ctx context.Context, validators domain.CacheValidators) ([]domain.Gist, domain.CacheValidators, error) {
 	// Use authenticated endpoint to get both public and private gists
-	url := fmt.Sprintf("%s/gists", c.baseURL)
+	url := fmt.Sprintf("%s/gists?per_page=%d", c.baseURL, c.perPage)
+	return getAllPages[domain.Gist](ctx, c, url, validators)
+}
+
+// GetUpdatedSince retrieves the gists updated at or after since, following
+// pagination like GetAll
+func (c *Client) GetUpdatedSince(ctx context.Context, since time.Time) ([]domain.Gist, error) {
+	// An RFC 3339 UTC timestamp needs no escaping in a query string
+	url := fmt.Sprintf("%s/gists?per_page=%d&since=%s", c.baseURL, c.perPage, since.UTC().Format(time.RFC3339))
+	gists, _, err := getAllPages[domain.Gist](ctx, c, url, domain.CacheValidators{})
+	return gists, err
+}
+
+// GetHistory retrieves every revision of a gist, newest first
+func (c *Client) GetHistory(ctx context.Context, id domain.GistID) ([]domain.GistRevision, error) {
+	url := fmt.Sprintf("%s/gists/%s/commits?per_page=%d", c.baseURL, id.String(), c.perPage)
+
+	revisions, _, err := getAllPages[domain.GistRevision](ctx, c, url, domain.CacheValidators{})
+	var apiErr domain.ErrAPIRequest
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && len(revisions) == 0 {
+		return nil, domain.ErrGistNotFound{ID: id}
+	}
+	return revisions, err
+}
+
+// GetRevision retrieves a gist as it was at the given revision SHA
+func (c *Client) GetRevision(ctx context.Context, id domain.GistID, sha string) (*domain.Gist, error) {
+	url := fmt.Sprintf("%s/gists/%s/%s", c.baseURL, id.String(), sha)
 
 	resp, err := c.apiRequest(ctx, "GET", url, nil)
 	if err != nil {
@@ -157,42 +261,302 @@ func (c *Client) GetAll(ctx context.Context) ([]domain.Gist, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrRevisionNotFound{ID: id, Revision: sha}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, c.handleAPIError(resp)
 	}
 
-	var gists []domain.Gist
-	if err := json.NewDecoder(resp.Body).Decode(&gists); err != nil {
-		return nil, fmt.Errorf("decode gists response: %w", err)
+	var gist domain.Gist
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return nil, fmt.Errorf("decode gist revision response: %w", err)
+	}
+
+	return &gist, nil
+}
+
+// maxRawContentBytes caps a raw file download. Gist API responses truncate
+// file contents at 1 MB, but the raw body of a file can be much larger.
+const maxRawContentBytes = 64 * 1024 * 1024
+
+// GetRawContent downloads a file body from its raw URL. The token is only
+// sent when the URL is on the configured API host: github.com serves raw
+// gist content from a separate host that does not need it, while GitHub
+// Enterprise Server serves it from the instance host, which may.
+func (c *Client) GetRawContent(ctx context.Context, rawURL string) (string, error) {
+	if c.offline {
+		return "", domain.ErrOffline{}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Gist-CLI")
+	if tokenURL(rawURL, c.baseURL, false) {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", c.transportError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", c.handleAPIError(resp)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRawContentBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("read raw content: %w", err)
+	}
+	if len(body) > maxRawContentBytes {
+		return "", fmt.Errorf("raw content at %s exceeds %d bytes", rawURL, maxRawContentBytes)
 	}
 
-	return gists, nil
+	return string(body), nil
+}
+
+// tokenURL reports whether rawURL may be sent the token of the API at base.
+// It must have the scheme and host of base and no user info, since
+// https://api.github.com@evil.example/ names evil.example. With underBase it
+// must also be under the path of base: pagination links stay within the API,
+// while GitHub Enterprise Server serves raw gist content from the instance
+// host outside it.
+func tokenURL(rawURL, base string, underBase bool) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	if u.User != nil || u.Scheme != b.Scheme || !strings.EqualFold(u.Host, b.Host) {
+		return false
+	}
+	if !underBase {
+		return true
+	}
+	basePath := strings.TrimSuffix(b.Path, "/")
+	return basePath == "" || u.Path == basePath || strings.HasPrefix(u.Path, basePath+"/")
+}
+
+// getAllPages fetches a list endpoint and every page linked from it with
+// rel="next". If a page after the first fails, the items fetched so far are
+// returned together with a domain.ErrPartialFetch. The first request is
+// conditional on validators; the validators of a single-page listing are
+// returned for the next call.
+func getAllPages[T any](ctx context.Context, c *Client, next string, validators domain.CacheValidators) ([]T, domain.CacheValidators, error) {
+	var all []T
+	var first domain.CacheValidators
+	visited := make(map[string]bool)
+	for page := 1; next != ""; page++ {
+		visited[next] = true
+		if page > 1 {
+			first = domain.CacheValidators{}
+			if err := c.waitForRateLimit(ctx); err != nil {
+				return all, first, domain.ErrPartialFetch{Page: page, Fetched: len(all), Err: err}
+			}
+		}
+
+		var items []T
+		link, pageValidators, err := c.getPage(ctx, next, validators, &items)
+		if err != nil {
+			if page == 1 {
+				return nil, domain.CacheValidators{}, err
+			}
+			return all, first, domain.ErrPartialFetch{Page: page, Fetched: len(all), Err: err}
+		}
+		all = append(all, items...)
+		if page == 1 {
+			first = pageValidators
+			validators = domain.CacheValidators{}
+		}
+
+		if visited[link] {
+			break // a server linking back to a page must not loop forever
+		}
+		if link != "" && !tokenURL(link, c.baseURL, true) {
+			// Never send the token to a host other than the configured API.
+			return all, domain.CacheValidators{}, domain.ErrPartialFetch{
+				Page:    page + 1,
+				Fetched: len(all),
+				Err:     fmt.Errorf("next page link %q is outside %s", link, c.baseURL),
+			}
+		}
+		next = link
+	}
+
+	return all, first, nil
+}
+
+// getPage fetches a single page of a list endpoint into v and returns the
+// rel="next" link, if any, and the response validators. A 304 response to a
+// conditional request yields domain.ErrNotModified.
+func (c *Client) getPage(ctx context.Context, url string, validators domain.CacheValidators, v any) (string, domain.CacheValidators, error) {
+	resp, err := c.conditionalRequest(ctx, "GET", url, nil, validators)
+	if err != nil {
+		return "", domain.CacheValidators{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !validators.Empty() {
+		return "", validators, domain.ErrNotModified{}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", domain.CacheValidators{}, c.handleAPIError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", domain.CacheValidators{}, fmt.Errorf("decode list response: %w", err)
+	}
+
+	return nextPageURL(resp.Header.Get("Link")), responseValidators(resp), nil
+}
+
+// responseValidators extracts the ETag and Last-Modified headers of resp
+func responseValidators(resp *http.Response) domain.CacheValidators {
+	return domain.CacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// waitForRateLimit blocks until the rate-limit window resets when the last
+// response reported an exhausted budget, so a multi-page fetch does not burn
+// requests on guaranteed 403s. The wait is capped at maxRateLimitWait.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	if c.rateLimit.remaining > 0 || c.rateLimit.reset.IsZero() {
+		return nil
+	}
+	wait := min(time.Until(c.rateLimit.reset), maxRateLimitWait)
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// nextPageURL extracts the rel="next" target from an RFC 8288 Link header.
+// Returns "" when there is no next page.
+func nextPageURL(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		sections := strings.Split(part, ";")
+		if len(sections) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(sections[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range sections[1:] {
+			param = strings.TrimSpace(param)
+			if rel, ok := strings.CutPrefix(param, "rel="); ok {
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					if r == "next" {
+						return strings.Trim(target, "<>")
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// GetAuthStatus fetches the authenticated user with GET /user, reading the
+// token's scopes from X-OAuth-Scopes and the rate limit left after the call.
+// An API URL that does not answer like a GitHub API gives
+// domain.ErrInvalidAPIURL.
+func (c *Client) GetAuthStatus(ctx context.Context) (*domain.AuthStatus, error) {
+	url := fmt.Sprintf("%s/user", c.baseURL)
+
+	resp, err := c.apiRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, domain.ErrBadCredentials{}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrInvalidAPIURL{URL: c.baseURL, Reason: "no GitHub API found at this URL"}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleAPIError(resp)
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil || user.Login == "" {
+		return nil, domain.ErrInvalidAPIURL{URL: c.baseURL, Reason: "the response is not a GitHub user"}
+	}
+
+	status := &domain.AuthStatus{
+		APIURL:             c.baseURL,
+		Enterprise:         resp.Header.Get("X-GitHub-Enterprise-Version"),
+		Login:              user.Login,
+		RateLimitRemaining: c.rateLimit.remaining,
+		RateLimitLimit:     c.rateLimit.limit,
+		RateLimitReset:     c.rateLimit.reset,
+	}
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		status.ScopesKnown = true
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				status.Scopes = append(status.Scopes, scope)
+			}
+		}
+	}
+
+	return status, nil
 }
 
 // GetByID retrieves a specific gist by ID
 func (c *Client) GetByID(ctx context.Context, id domain.GistID) (*domain.Gist, error) {
+	gist, _, err := c.GetByIDIfModified(ctx, id, domain.CacheValidators{})
+	return gist, err
+}
+
+// GetByIDIfModified is GetByID as a conditional request. When the gist still
+// matches validators it returns domain.ErrNotModified without downloading it.
+func (c *Client) GetByIDIfModified(ctx context.Context, id domain.GistID, validators domain.CacheValidators) (*domain.Gist, domain.CacheValidators, error) {
 	url := fmt.Sprintf("%s/gists/%s", c.baseURL, id.String())
 
-	resp, err := c.apiRequest(ctx, "GET", url, nil)
+	resp, err := c.conditionalRequest(ctx, "GET", url, nil, validators)
 	if err != nil {
-		return nil, err
+		return nil, domain.CacheValidators{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && !validators.Empty() {
+		return nil, validators, domain.ErrNotModified{}
+	}
+
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, domain.ErrGistNotFound{ID: id}
+		return nil, domain.CacheValidators{}, domain.ErrGistNotFound{ID: id}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleAPIError(resp)
+		return nil, domain.CacheValidators{}, c.handleAPIError(resp)
 	}
 
 	var gist domain.Gist
 	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
-		return nil, fmt.Errorf("decode gist response: %w", err)
+		return nil, domain.CacheValidators{}, fmt.Errorf("decode gist response: %w", err)
 	}
 
-	return &gist, nil
+	return &gist, responseValidators(resp), nil
 }
 
 // Create creates a new gist
@@ -235,11 +599,17 @@ func (c *Client) Create(ctx context.Context, gist *domain.Gist) error {
 	return nil
 }
 
-// Update updates an existing gist
+// Update updates an existing gist. Only the files present in gist.Files are
+// sent; on success gist is refreshed from the response so it reflects the
+// full remote state, including files that were not part of the update.
 func (c *Client) Update(ctx context.Context, gist *domain.Gist) error {
+	files, err := c.formatFileChanges(gist.Files)
+	if err != nil {
+		return err
+	}
 	payload := map[string]interface{}{
 		"description": gist.Description,
-		"files":       c.formatFiles(gist.Files),
+		"files":       files,
 	}
 
 	body, err := json.Marshal(payload)
@@ -254,10 +624,20 @@ func (c *Client) Update(ctx context.Context, gist *domain.Gist) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.ErrGistNotFound{ID: gist.ID}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return c.handleAPIError(resp)
 	}
 
+	var updated domain.Gist
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return fmt.Errorf("decode updated gist: %w", err)
+	}
+	*gist = updated
+
 	return nil
 }
 
@@ -283,10 +663,15 @@ func (c *Client) Delete(ctx context.Context, id domain.GistID) error {
 // inflate memory or the error message.
 const maxAPIErrorBodyBytes = 64 * 1024
 
-// handleAPIError creates a domain error from an HTTP response. The response
-// body is capped at maxAPIErrorBodyBytes; if truncation occurs a marker is
-// appended so callers can tell the message is incomplete.
+// handleAPIError creates a domain error from an HTTP response
 func (c *Client) handleAPIError(resp *http.Response) error {
+	return apiError(resp)
+}
+
+// apiError creates a domain error from an HTTP response. The response body
+// is capped at maxAPIErrorBodyBytes; if truncation occurs a marker is
+// appended so callers can tell the message is incomplete.
+func apiError(resp *http.Response) error {
 	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAPIErrorBodyBytes+1))
 	truncated := false
 	if len(body) > maxAPIErrorBodyBytes {
@@ -308,6 +693,10 @@ func (c *Client) formatFiles(files map[string]domain.GistFile) map[string]map[st
 	apiFiles := make(map[string]map[string]string)
 
 	for filename, file := range files {
+		if file.Deleted {
+			continue // nothing to delete in a new gist
+		}
+
 		// Use the filename from the key, but prefer the one in the file struct if set
 		name := filename
 		if file.Filename != "" {
@@ -321,3 +710,34 @@ func (c *Client) formatFiles(files map[string]domain.GistFile) map[string]map[st
 
 	return apiFiles
 }
+
+// formatFileChanges converts domain.GistFile changes to the GitHub update
+// format. Keys name the current remote file: deleted files are sent as null,
+// and a differing Filename is sent as a rename. Content is omitted for a
+// pure rename so the file body is left untouched. Any other change without
+// content empties the file, which GitHub would silently ignore, so it is
+// refused with domain.ErrEmptyFile.
+func (c *Client) formatFileChanges(files map[string]domain.GistFile) (map[string]interface{}, error) {
+	apiFiles := make(map[string]interface{})
+
+	for filename, file := range files {
+		if file.Deleted {
+			apiFiles[filename] = nil
+			continue
+		}
+
+		change := map[string]string{}
+		if file.Filename != "" && file.Filename != filename {
+			change["filename"] = file.Filename
+		}
+		if file.Content != "" {
+			change["content"] = file.Content
+		}
+		if len(change) == 0 {
+			return nil, domain.ErrEmptyFile{Filename: filename}
+		}
+		apiFiles[filename] = change
+	}
+
+	return apiFiles, nil
+}
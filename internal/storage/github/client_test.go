@@ -2,9 +2,13 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -35,16 +39,19 @@ type respSpec struct {
 // scriptedHandler returns the scripted responses in order, then a 200 okBody
 // default once the script is exhausted. It records the call count and methods.
 type scriptedHandler struct {
-	mu       sync.Mutex
+	mu        sync.Mutex
 	responses []respSpec
-	calls    int
-	methods  []string
+	calls     int
+	methods   []string
+	bodies    []string
 }
 
 func (h *scriptedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.mu.Lock()
 	h.calls++
 	h.methods = append(h.methods, r.Method)
+	body, _ := io.ReadAll(r.Body)
+	h.bodies = append(h.bodies, string(body))
 	idx := h.calls - 1
 	rs := respSpec{status: http.StatusOK, body: okBody}
 	if idx < len(h.responses) {
@@ -84,6 +91,32 @@ func TestClient_GetByID_Success(t *testing.T) {
 	}
 }
 
+func TestClient_GetByID_DecodesFileMetadata(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{
+		status: http.StatusOK,
+		body:   `{"id":"abc","files":{"big.go":{"filename":"big.go","type":"text/plain","language":"Go","raw_url":"https://raw/big.go","size":2000000,"truncated":true,"content":"package"}}}`,
+	}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	g, err := newTestClient(t, srv).GetByID(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	want := domain.GistFile{
+		Filename:  "big.go",
+		Content:   "package",
+		RawURL:    "https://raw/big.go",
+		Size:      2000000,
+		Language:  "Go",
+		Type:      "text/plain",
+		Truncated: true,
+	}
+	if got := g.Files["big.go"]; got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
 func TestClient_GET_RetriesOn5xxThenSucceeds(t *testing.T) {
 	h := &scriptedHandler{responses: []respSpec{
 		{status: http.StatusInternalServerError, body: `{"message":"err"}`},
@@ -193,3 +226,753 @@ func TestClient_ContextCancel(t *testing.T) {
 		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
+
+// pagedHandler serves /gists as a sequence of pages linked with
+// Link rel="next" headers. A status in failOn makes that page fail.
+type pagedHandler struct {
+	mu      sync.Mutex
+	pages   [][]string
+	failOn  map[int]int
+	queries []string
+	srvURL  string
+}
+
+func (h *pagedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.queries = append(h.queries, r.URL.RawQuery)
+	h.mu.Unlock()
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page == 0 {
+		page = 1
+	}
+	if status, ok := h.failOn[page]; ok {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":"page failed"}`))
+		return
+	}
+	if page < len(h.pages) {
+		w.Header().Set("Link", fmt.Sprintf(
+			`<%s/gists?per_page=%s&page=%d>; rel="next", <%s/gists?per_page=%s&page=%d>; rel="last"`,
+			h.srvURL, r.URL.Query().Get("per_page"), page+1,
+			h.srvURL, r.URL.Query().Get("per_page"), len(h.pages)))
+	}
+	var items []string
+	for _, id := range h.pages[page-1] {
+		items = append(items, fmt.Sprintf(`{"id":%q}`, id))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte("[" + strings.Join(items, ",") + "]"))
+}
+
+func TestClient_GetAll_FollowsNextLinks(t *testing.T) {
+	h := &pagedHandler{pages: [][]string{{"a", "b"}, {"c", "d"}, {"e"}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	h.srvURL = srv.URL
+
+	c := newTestClient(t, srv)
+	c.SetPerPage(2)
+	gists, err := c.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	var ids []string
+	for _, g := range gists {
+		ids = append(ids, string(g.ID))
+	}
+	if got := strings.Join(ids, ","); got != "a,b,c,d,e" {
+		t.Errorf("expected all pages aggregated, got %s", got)
+	}
+	if len(h.queries) != 3 {
+		t.Fatalf("expected 3 page requests, got %d", len(h.queries))
+	}
+	if !strings.Contains(h.queries[0], "per_page=2") {
+		t.Errorf("expected per_page=2 on first request, got %q", h.queries[0])
+	}
+}
+
+func TestClient_GetAll_StopsOnLinkCycle(t *testing.T) {
+	requests := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 10 {
+			t.Error("pagination kept following the cycle")
+			http.Error(w, "stop", http.StatusBadRequest)
+			return
+		}
+		// page 2 and page 3 link to each other
+		next := map[string]string{"": "2", "2": "3", "3": "2"}[r.URL.Query().Get("page")]
+		w.Header().Set("Link", "<"+srv.URL+"/gists?page="+next+`>; rel="next"`)
+		_, _ = w.Write([]byte(`[{"id":"g` + strconv.Itoa(requests) + `"}]`))
+	}))
+	defer srv.Close()
+
+	gists, err := newTestClient(t, srv).GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(gists) != 3 || requests != 3 {
+		t.Errorf("expected each page fetched once, got %d gists in %d requests", len(gists), requests)
+	}
+}
+
+func TestClient_GetAll_PartialFailure(t *testing.T) {
+	h := &pagedHandler{
+		pages:  [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		failOn: map[int]int{2: http.StatusNotFound},
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	h.srvURL = srv.URL
+
+	c := newTestClient(t, srv)
+	c.SetPerPage(2)
+	gists, err := c.GetAll(context.Background())
+	var partial domain.ErrPartialFetch
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected domain.ErrPartialFetch, got %T: %v", err, err)
+	}
+	if partial.Page != 2 || partial.Fetched != 2 {
+		t.Errorf("expected failure on page 2 after 2 gists, got %+v", partial)
+	}
+	var apiErr domain.ErrAPIRequest
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected wrapped 404 API error, got %v", err)
+	}
+	if len(gists) != 2 {
+		t.Errorf("expected the first page returned alongside the error, got %d", len(gists))
+	}
+}
+
+func TestClient_GetAll_FirstPageErrorNotPartial(t *testing.T) {
+	h := &pagedHandler{
+		pages:  [][]string{{"a"}},
+		failOn: map[int]int{1: http.StatusUnauthorized},
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	h.srvURL = srv.URL
+
+	_, err := newTestClient(t, srv).GetAll(context.Background())
+	var partial domain.ErrPartialFetch
+	if errors.As(err, &partial) {
+		t.Fatalf("first-page failure should not be reported as partial: %v", err)
+	}
+	var apiErr domain.ErrAPIRequest
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 API error, got %v", err)
+	}
+}
+
+// recordingTransport records the URL of every request the client attempts,
+// including those to hosts that cannot be reached
+type recordingTransport struct {
+	urls []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_GetAll_RejectsForeignNextLink(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	for _, link := range []string{
+		"https://evil.example/gists?page=2",
+		srv.URL + ".evil.example/gists?page=2",
+		srv.URL + "@evil.example/gists?page=2",
+	} {
+		srv.Config.Handler = &scriptedHandler{responses: []respSpec{{
+			status:  http.StatusOK,
+			body:    `[{"id":"a"}]`,
+			headers: map[string]string{"Link": "<" + link + `>; rel="next"`},
+		}}}
+		transport := &recordingTransport{}
+		c := newTestClient(t, srv)
+		c.httpClient.Transport = transport
+
+		gists, err := c.GetAll(context.Background())
+		var partial domain.ErrPartialFetch
+		if !errors.As(err, &partial) {
+			t.Fatalf("expected domain.ErrPartialFetch for %s, got %v", link, err)
+		}
+		if len(gists) != 1 || len(transport.urls) != 1 {
+			t.Errorf("%s: expected 1 gist and 1 request, got %d gists, requests %v", link, len(gists), transport.urls)
+		}
+	}
+}
+
+func TestTokenURL(t *testing.T) {
+	const ghes = "https://ghe.corp/api/v3"
+	tests := []struct {
+		link      string
+		base      string
+		underBase bool
+		want      bool
+	}{
+		{"https://api.github.com/gists?page=2", "https://api.github.com", true, true},
+		{"https://API.github.com/gists?page=2", "https://api.github.com", true, true},
+		{"http://api.github.com/gists?page=2", "https://api.github.com", true, false},
+		{"https://evil.example/gists?page=2", "https://api.github.com", true, false},
+		{"https://api.github.com.evil.example/gists?page=2", "https://api.github.com", true, false},
+		{"https://api.github.com@evil.example/gists?page=2", "https://api.github.com", true, false},
+		{"https://user@api.github.com/gists?page=2", "https://api.github.com", true, false},
+		{"https://gist.githubusercontent.com/u/abc/raw/f.txt", "https://api.github.com", false, false},
+
+		// GitHub Enterprise Server serves the API under a path
+		{"https://ghe.corp/api/v3/gists?page=2", ghes, true, true},
+		{"https://ghe.corp/api/v3x/gists?page=2", ghes, true, false},
+		{"https://ghe.corp/api/v3.evil.example/gists?page=2", ghes, true, false},
+		{"https://ghe.corp/gists?page=2", ghes, true, false},
+		{"https://ghe.corp/gist/raw/abc/sha/f.txt", ghes, false, true},
+		{"https://ghe.corp.evil.example/gist/raw/abc/sha/f.txt", ghes, false, false},
+		{"https://ghe.corp@evil.example/gist/raw/abc/sha/f.txt", ghes, false, false},
+	}
+	for _, tt := range tests {
+		if got := tokenURL(tt.link, tt.base, tt.underBase); got != tt.want {
+			t.Errorf("tokenURL(%q, %q, %v) = %v, want %v", tt.link, tt.base, tt.underBase, got, tt.want)
+		}
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{`<https://api.github.com/gists?page=2>; rel="next"`, "https://api.github.com/gists?page=2"},
+		{`<https://x/gists?page=1>; rel="prev", <https://x/gists?page=3>; rel="next"`, "https://x/gists?page=3"},
+		{`<https://x/gists?page=5>; rel="last"`, ""},
+	}
+	for _, tt := range tests {
+		if got := nextPageURL(tt.header); got != tt.want {
+			t.Errorf("nextPageURL(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Update_RefreshesFromResponse(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{
+		status: http.StatusOK,
+		body:   `{"id":"abc123def4567890","description":"hello","files":{"a.md":{"filename":"a.md","content":"x"},"b.md":{"filename":"b.md","content":"y"}}}`,
+	}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	g := &domain.Gist{ID: "abc123def4567890", Files: map[string]domain.GistFile{"a.md": {Content: "x"}}}
+	if err := newTestClient(t, srv).Update(context.Background(), g); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if h.methods[0] != http.MethodPatch {
+		t.Errorf("expected PATCH, got %s", h.methods[0])
+	}
+	if len(g.Files) != 2 {
+		t.Errorf("expected gist refreshed with both remote files, got %+v", g.Files)
+	}
+}
+
+func TestClient_Update_NotFound(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{status: http.StatusNotFound, body: `{"message":"Not Found"}`}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	err := newTestClient(t, srv).Update(context.Background(), &domain.Gist{ID: "missing"})
+	var nf domain.ErrGistNotFound
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected domain.ErrGistNotFound, got %v", err)
+	}
+}
+
+// updateFiles runs Update with the given file changes and returns the decoded
+// "files" object of the PATCH body.
+func updateFiles(t *testing.T, files map[string]domain.GistFile) map[string]json.RawMessage {
+	t.Helper()
+	h := &scriptedHandler{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	g := &domain.Gist{ID: "abc123def4567890", Description: "d", Files: files}
+	if err := newTestClient(t, srv).Update(context.Background(), g); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	var payload struct {
+		Files map[string]json.RawMessage `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(h.bodies[0]), &payload); err != nil {
+		t.Fatalf("decode PATCH body %q: %v", h.bodies[0], err)
+	}
+	return payload.Files
+}
+
+func TestClient_Update_FileOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(g *domain.Gist)
+		key   string
+		want  string
+	}{
+		{
+			name:  "add",
+			build: func(g *domain.Gist) { g.AddFile("new.md", "fresh") },
+			key:   "new.md",
+			want:  `{"content":"fresh"}`,
+		},
+		{
+			name:  "modify",
+			build: func(g *domain.Gist) { g.AddFile("post.md", "edited") },
+			key:   "post.md",
+			want:  `{"content":"edited"}`,
+		},
+		{
+			name:  "rename",
+			build: func(g *domain.Gist) { g.RenameFile("post.md", "renamed.md") },
+			key:   "post.md",
+			want:  `{"filename":"renamed.md"}`,
+		},
+		{
+			name: "rename and modify",
+			build: func(g *domain.Gist) {
+				g.AddFile("post.md", "edited")
+				g.RenameFile("post.md", "renamed.md")
+			},
+			key:  "post.md",
+			want: `{"content":"edited","filename":"renamed.md"}`,
+		},
+		{
+			name:  "delete",
+			build: func(g *domain.Gist) { g.RemoveFile("post.md") },
+			key:   "post.md",
+			want:  `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := domain.NewGist("abc123def4567890", "d", true)
+			tt.build(g)
+
+			files := updateFiles(t, g.Files)
+			got, ok := files[tt.key]
+			if !ok {
+				t.Fatalf("expected key %q in files, got %v", tt.key, files)
+			}
+			if string(got) != tt.want {
+				t.Errorf("files[%q] = %s, want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Update_RefusesEmptiedFile(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{status: http.StatusOK, body: okBody}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	g := domain.NewGist("abc123def4567890", "d", true)
+	g.AddFile("keep.md", "x")
+	g.AddFile("post.md", "")
+	err := newTestClient(t, srv).Update(context.Background(), g)
+	var empty domain.ErrEmptyFile
+	if !errors.As(err, &empty) || empty.Filename != "post.md" {
+		t.Fatalf("expected domain.ErrEmptyFile for post.md, got %v", err)
+	}
+	if h.count() != 0 {
+		t.Errorf("expected no request for an update GitHub would ignore, got %d", h.count())
+	}
+}
+
+func TestClient_Create_SkipsDeletedFiles(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{status: http.StatusCreated, body: okBody}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	g := domain.NewGist("", "d", true)
+	g.AddFile("keep.md", "x")
+	g.RemoveFile("gone.md")
+	if err := newTestClient(t, srv).Create(context.Background(), g); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if strings.Contains(h.bodies[0], "gone.md") {
+		t.Errorf("deleted file must not be sent on create: %s", h.bodies[0])
+	}
+}
+
+func TestClient_GetHistory(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{
+		status: http.StatusOK,
+		body:   `[{"version":"b2","committed_at":"2024-01-02T00:00:00Z","change_status":{"total":3,"additions":2,"deletions":1}},{"version":"a1","committed_at":"2024-01-01T00:00:00Z"}]`,
+	}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	history, err := newTestClient(t, srv).GetHistory(context.Background(), "abc123def4567890")
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].Version != "b2" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if history[0].ChangeStatus.Additions != 2 || history[0].ChangeStatus.Deletions != 1 {
+		t.Errorf("change status not decoded: %+v", history[0].ChangeStatus)
+	}
+}
+
+func TestClient_GetHistory_NotFound(t *testing.T) {
+	h := &scriptedHandler{responses: []respSpec{{status: http.StatusNotFound, body: `{"message":"Not Found"}`}}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	_, err := newTestClient(t, srv).GetHistory(context.Background(), "missing")
+	var nf domain.ErrGistNotFound
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected domain.ErrGistNotFound, got %v", err)
+	}
+}
+
+func TestClient_GetRevision(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if strings.HasSuffix(r.URL.Path, "/unknown") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"abc","files":{"a.md":{"filename":"a.md","content":"old"}}}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	g, err := c.GetRevision(context.Background(), "abc", "sha1")
+	if err != nil {
+		t.Fatalf("GetRevision: %v", err)
+	}
+	if path != "/gists/abc/sha1" {
+		t.Errorf("unexpected request path %q", path)
+	}
+	if g.Files["a.md"].Content != "old" {
+		t.Errorf("unexpected revision content: %+v", g.Files)
+	}
+
+	_, err = c.GetRevision(context.Background(), "abc", "unknown")
+	var nf domain.ErrRevisionNotFound
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected domain.ErrRevisionNotFound, got %v", err)
+	}
+}
+
+func TestClient_GetRawContent_TokenOnlyForAPIHost(t *testing.T) {
+	var apiAuth, rawAuth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("api body"))
+	}))
+	defer api.Close()
+	raw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("full content"))
+	}))
+	defer raw.Close()
+	c := newTestClient(t, api)
+
+	content, err := c.GetRawContent(context.Background(), raw.URL+"/user/abc/raw/sha/big.txt")
+	if err != nil {
+		t.Fatalf("GetRawContent: %v", err)
+	}
+	if content != "full content" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if rawAuth != "" {
+		t.Errorf("token leaked to foreign host: %q", rawAuth)
+	}
+
+	if _, err := c.GetRawContent(context.Background(), api.URL+"/raw/big.txt"); err != nil {
+		t.Fatalf("GetRawContent on API host: %v", err)
+	}
+	if apiAuth != "Bearer test-token" {
+		t.Errorf("expected token on API host, got %q", apiAuth)
+	}
+}
+
+func TestClient_GetAllIfModified(t *testing.T) {
+	var gotETag, gotSince string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotETag = r.Header.Get("If-None-Match")
+		gotSince = r.Header.Get("If-Modified-Since")
+		if gotETag == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		_, _ = w.Write([]byte(`[` + okBody + `]`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	gists, validators, err := c.GetAllIfModified(context.Background(), domain.CacheValidators{})
+	if err != nil {
+		t.Fatalf("GetAllIfModified: %v", err)
+	}
+	if gotETag != "" || gotSince != "" {
+		t.Errorf("unconditional request sent validators: %q %q", gotETag, gotSince)
+	}
+	if len(gists) != 1 || validators.ETag != `"v1"` || validators.LastModified == "" {
+		t.Fatalf("unexpected result: %+v %+v", gists, validators)
+	}
+
+	gists, _, err = c.GetAllIfModified(context.Background(), validators)
+	var nm domain.ErrNotModified
+	if !errors.As(err, &nm) {
+		t.Fatalf("expected domain.ErrNotModified, got %v", err)
+	}
+	if gists != nil {
+		t.Errorf("expected no gists on 304, got %+v", gists)
+	}
+	if gotSince != validators.LastModified {
+		t.Errorf("expected If-Modified-Since %q, got %q", validators.LastModified, gotSince)
+	}
+}
+
+func TestClient_GetAllIfModified_MultiPageHasNoValidators(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"page"`)
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/gists?page=2>; rel="next"`, srvURL))
+		}
+		_, _ = w.Write([]byte(`[` + okBody + `]`))
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	gists, validators, err := newTestClient(t, srv).GetAllIfModified(context.Background(), domain.CacheValidators{})
+	if err != nil {
+		t.Fatalf("GetAllIfModified: %v", err)
+	}
+	if len(gists) != 2 {
+		t.Fatalf("expected 2 gists, got %d", len(gists))
+	}
+	if !validators.Empty() {
+		t.Errorf("expected no validators for a multi-page listing, got %+v", validators)
+	}
+}
+
+func TestClient_GetByIDIfModified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"g1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"g1"`)
+		_, _ = w.Write([]byte(okBody))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	g, validators, err := c.GetByIDIfModified(context.Background(), "abc123def4567890", domain.CacheValidators{})
+	if err != nil || g == nil || validators.ETag != `"g1"` {
+		t.Fatalf("unexpected result: %+v %+v %v", g, validators, err)
+	}
+
+	_, _, err = c.GetByIDIfModified(context.Background(), "abc123def4567890", validators)
+	var nm domain.ErrNotModified
+	if !errors.As(err, &nm) {
+		t.Fatalf("expected domain.ErrNotModified, got %v", err)
+	}
+}
+
+func TestClient_GetUpdatedSince(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("since")
+		_, _ = w.Write([]byte(`[` + okBody + `]`))
+	}))
+	defer srv.Close()
+
+	since := time.Date(2024, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+	gists, err := newTestClient(t, srv).GetUpdatedSince(context.Background(), since)
+	if err != nil {
+		t.Fatalf("GetUpdatedSince: %v", err)
+	}
+	if len(gists) != 1 {
+		t.Errorf("expected 1 gist, got %d", len(gists))
+	}
+	if query != "2024-03-04T04:06:07Z" {
+		t.Errorf("expected since in UTC, got %q", query)
+	}
+}
+
+func TestClient_Offline(t *testing.T) {
+	h := &scriptedHandler{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	c := newTestClient(t, srv)
+	c.SetOffline(true)
+
+	_, err := c.GetAll(context.Background())
+	var offline domain.ErrOffline
+	if !errors.As(err, &offline) {
+		t.Fatalf("expected domain.ErrOffline, got %v", err)
+	}
+	if _, err := c.GetRawContent(context.Background(), srv.URL+"/raw"); !errors.As(err, &offline) {
+		t.Fatalf("expected domain.ErrOffline for raw content, got %v", err)
+	}
+	if h.count() != 0 {
+		t.Errorf("offline client made %d requests", h.count())
+	}
+}
+
+func TestClient_UnreachableIsErrNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	c := newTestClient(t, srv)
+	srv.Close() // nothing listens any more
+
+	_, err := c.GetByID(context.Background(), "abc")
+	var network domain.ErrNetwork
+	if !errors.As(err, &network) {
+		t.Fatalf("expected domain.ErrNetwork, got %v", err)
+	}
+}
+
+func TestClient_GetAuthStatus(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	h := &scriptedHandler{responses: []respSpec{
+		{status: http.StatusOK, body: `{"login":"test-user"}`, headers: map[string]string{
+			"X-OAuth-Scopes":        "gist, read:user",
+			"X-RateLimit-Remaining": "4999",
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		}},
+		{status: http.StatusOK, body: `{"login":"test-user"}`},
+		{status: http.StatusOK, body: `{"login":"test-user"}`, headers: map[string]string{"X-OAuth-Scopes": ""}},
+		{status: http.StatusUnauthorized, body: `{"message":"Bad credentials"}`},
+	}}
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	status, err := c.GetAuthStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetAuthStatus: %v", err)
+	}
+	if path != "/user" {
+		t.Errorf("expected GET /user, got %s", path)
+	}
+	if status.Login != "test-user" || !status.ScopesKnown || !status.HasScope("gist") || len(status.Scopes) != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.RateLimitRemaining != 4999 || status.RateLimitLimit != 5000 || status.RateLimitReset.Unix() != reset {
+		t.Errorf("expected the rate limit from the headers, got %+v", status)
+	}
+
+	// Fine-grained tokens send no scopes header
+	if status, err := c.GetAuthStatus(context.Background()); err != nil || status.ScopesKnown {
+		t.Errorf("expected unknown scopes, got %+v err=%v", status, err)
+	}
+
+	// A classic token without scopes sends an empty header
+	if status, err := c.GetAuthStatus(context.Background()); err != nil || !status.ScopesKnown || len(status.Scopes) != 0 {
+		t.Errorf("expected known empty scopes, got %+v err=%v", status, err)
+	}
+
+	var badCreds domain.ErrBadCredentials
+	if _, err := c.GetAuthStatus(context.Background()); !errors.As(err, &badCreds) {
+		t.Errorf("expected domain.ErrBadCredentials, got %v", err)
+	}
+}
+
+func TestClient_GetAll_RejectsLinkOutsideAPIPath(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	for _, link := range []string{
+		srv.URL + "/api/v3x/gists?page=2",
+		srv.URL + "/api/v3.evil.example/gists?page=2",
+	} {
+		h := &scriptedHandler{responses: []respSpec{{
+			status:  http.StatusOK,
+			body:    `[{"id":"a"}]`,
+			headers: map[string]string{"Link": "<" + link + `>; rel="next"`},
+		}}}
+		srv.Config.Handler = h
+		c := newTestClient(t, srv)
+		c.SetBaseURL(srv.URL + "/api/v3")
+
+		gists, err := c.GetAll(context.Background())
+		var partial domain.ErrPartialFetch
+		if !errors.As(err, &partial) {
+			t.Fatalf("expected domain.ErrPartialFetch for %s, got %v", link, err)
+		}
+		if len(gists) != 1 || h.count() != 1 {
+			t.Errorf("%s: expected 1 gist and 1 request, got %d gists, %d requests", link, len(gists), h.count())
+		}
+	}
+}
+
+func TestClient_EnterpriseBaseURL(t *testing.T) {
+	var paths []string
+	var rawAuth string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		switch {
+		case r.URL.Path == "/api/v3/gists" && r.URL.Query().Get("page") == "":
+			w.Header().Set("Link", `<`+srv.URL+`/api/v3/gists?per_page=100&page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[` + okBody + `]`))
+		case r.URL.Path == "/api/v3/gists":
+			_, _ = w.Write([]byte(`[]`))
+		case r.URL.Path == "/api/v3/user":
+			w.Header().Set("X-GitHub-Enterprise-Version", "3.12.0")
+			_, _ = w.Write([]byte(`{"login":"test-user"}`))
+		case strings.HasPrefix(r.URL.Path, "/gist/raw/"):
+			rawAuth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte("raw body"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+	c.SetBaseURL(srv.URL + "/api/v3/")
+
+	gists, err := c.GetAll(context.Background())
+	if err != nil || len(gists) != 1 {
+		t.Fatalf("GetAll = %d gists, %v; want 1", len(gists), err)
+	}
+	if len(paths) != 2 || paths[1] != "/api/v3/gists?per_page=100&page=2" {
+		t.Errorf("expected the next page followed under /api/v3, got %v", paths)
+	}
+
+	// The instance serves raw content itself, so it gets the token
+	if _, err := c.GetRawContent(context.Background(), srv.URL+"/gist/raw/abc/sha/f.txt"); err != nil {
+		t.Fatalf("GetRawContent: %v", err)
+	}
+	if rawAuth != "Bearer test-token" {
+		t.Errorf("expected the token sent to the instance host, got %q", rawAuth)
+	}
+
+	status, err := c.GetAuthStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetAuthStatus: %v", err)
+	}
+	if status.APIURL != srv.URL+"/api/v3" || status.Enterprise != "3.12.0" {
+		t.Errorf("expected the Enterprise API reported, got %+v", status)
+	}
+
+	// A URL without the API behind it is reported as such
+	c.SetBaseURL(srv.URL)
+	var invalid domain.ErrInvalidAPIURL
+	if _, err := c.GetAuthStatus(context.Background()); !errors.As(err, &invalid) {
+		t.Errorf("expected domain.ErrInvalidAPIURL, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// DefaultOAuthURL is the base URL of GitHub's OAuth endpoints
+const DefaultOAuthURL = "https://github.com"
+
+// deviceGrantType is the OAuth grant type of device flow token requests
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// slowDownStep is how much a slow_down response lengthens the poll interval
+const slowDownStep = 5 * time.Second
+
+// defaultPollInterval is the poll interval when GitHub names none, as
+// RFC 8628 specifies
+const defaultPollInterval = 5 * time.Second
+
+// DeviceFlow implements the DeviceAuthRepository interface with GitHub's
+// OAuth device authorization flow, which signs in an OAuth app without the
+// user pasting a token
+type DeviceFlow struct {
+	httpClient *http.Client
+	baseURL    string
+	clientID   string
+	// sleep waits between polls; tests replace it to run without delay
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// deviceResponse is the union of the device code and token responses.
+// GitHub reports a pending or failed authorization as a 200 with error set.
+type deviceResponse struct {
+	DeviceCode       string `json:"device_code"`
+	UserCode         string `json:"user_code"`
+	VerificationURI  string `json:"verification_uri"`
+	ExpiresIn        int    `json:"expires_in"`
+	Interval         int    `json:"interval"`
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewDeviceFlow creates a device flow for the OAuth app clientID against the
+// OAuth endpoints at baseURL, normally DefaultOAuthURL
+func NewDeviceFlow(baseURL, clientID string) *DeviceFlow {
+	return &DeviceFlow{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		clientID:   clientID,
+		sleep:      sleepContext,
+	}
+}
+
+// RequestDeviceCode starts an authorization for scopes
+func (f *DeviceFlow) RequestDeviceCode(ctx context.Context, scopes []string) (*domain.DeviceCode, error) {
+	if f.clientID == "" {
+		return nil, domain.ErrConfigMissing{Field: "OAuth client ID (GIST_OAUTH_CLIENT_ID)"}
+	}
+
+	resp, err := f.post(ctx, "/login/device/code", url.Values{
+		"client_id": {f.clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, domain.ErrDeviceFlow{Code: resp.Error, Description: resp.ErrorDescription}
+	}
+
+	code := &domain.DeviceCode{
+		DeviceCode:      resp.DeviceCode,
+		UserCode:        resp.UserCode,
+		VerificationURI: resp.VerificationURI,
+		Interval:        time.Duration(resp.Interval) * time.Second,
+	}
+	// Without expires_in the code has no deadline of ours; GitHub still
+	// answers expired_token once it lapses
+	if resp.ExpiresIn > 0 {
+		code.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	if code.Interval <= 0 {
+		code.Interval = defaultPollInterval
+	}
+	return code, nil
+}
+
+// PollDeviceToken polls until the user completes the authorization of code
+// and returns the access token. It waits code.Interval, or 5s if unset,
+// between polls and backs off when GitHub answers slow_down.
+func (f *DeviceFlow) PollDeviceToken(ctx context.Context, code *domain.DeviceCode) (string, error) {
+	interval := code.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		if err := f.sleep(ctx, interval); err != nil {
+			return "", err
+		}
+		if !code.ExpiresAt.IsZero() && time.Now().After(code.ExpiresAt) {
+			return "", domain.ErrDeviceFlow{Code: "expired_token", Description: "the device code expired"}
+		}
+
+		resp, err := f.post(ctx, "/login/oauth/access_token", url.Values{
+			"client_id":   {f.clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {deviceGrantType},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		switch resp.Error {
+		case "":
+			if resp.AccessToken == "" {
+				return "", fmt.Errorf("token response holds no access token")
+			}
+			return resp.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			if resp.Interval > 0 {
+				interval = time.Duration(resp.Interval) * time.Second
+			} else {
+				interval += slowDownStep
+			}
+		default:
+			return "", domain.ErrDeviceFlow{Code: resp.Error, Description: resp.ErrorDescription}
+		}
+	}
+}
+
+// post sends a form to an OAuth endpoint and decodes the JSON answer
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values) (*deviceResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", f.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "Gist-CLI")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, domain.ErrNetwork{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var decoded deviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return &decoded, nil
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+// newTestDeviceFlow points a DeviceFlow at the test server, recording the
+// poll intervals instead of sleeping
+func newTestDeviceFlow(t *testing.T, srv *httptest.Server) (*DeviceFlow, *[]time.Duration) {
+	t.Helper()
+	f := NewDeviceFlow(srv.URL+"/", "client-123")
+	var waits []time.Duration
+	f.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return f, &waits
+}
+
+func TestDeviceFlow_Login(t *testing.T) {
+	var forms []url.Values
+	tokenResponses := []string{
+		`{"error":"authorization_pending"}`,
+		`{"error":"slow_down","interval":10}`,
+		`{"error":"slow_down"}`,
+		`{"access_token":"gho_token","token_type":"bearer","scope":"gist"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		forms = append(forms, r.PostForm)
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("expected a JSON Accept header, got %q", r.Header.Get("Accept"))
+		}
+		switch r.URL.Path {
+		case "/login/device/code":
+			_, _ = w.Write([]byte(`{"device_code":"dev-code","user_code":"ABCD-1234",` +
+				`"verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`))
+		case "/login/oauth/access_token":
+			_, _ = w.Write([]byte(tokenResponses[0]))
+			tokenResponses = tokenResponses[1:]
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	f, waits := newTestDeviceFlow(t, srv)
+
+	code, err := f.RequestDeviceCode(context.Background(), []string{"gist"})
+	if err != nil {
+		t.Fatalf("RequestDeviceCode: %v", err)
+	}
+	if code.UserCode != "ABCD-1234" || code.VerificationURI != "https://github.com/login/device" || code.Interval != 5*time.Second {
+		t.Errorf("unexpected device code %+v", code)
+	}
+	if forms[0].Get("client_id") != "client-123" || forms[0].Get("scope") != "gist" {
+		t.Errorf("unexpected device code request %v", forms[0])
+	}
+
+	token, err := f.PollDeviceToken(context.Background(), code)
+	if err != nil {
+		t.Fatalf("PollDeviceToken: %v", err)
+	}
+	if token != "gho_token" {
+		t.Errorf("token = %q, want gho_token", token)
+	}
+
+	last := forms[len(forms)-1]
+	if last.Get("device_code") != "dev-code" || last.Get("grant_type") != deviceGrantType {
+		t.Errorf("unexpected token request %v", last)
+	}
+
+	// pending keeps the interval, slow_down takes the new one or adds 5s
+	want := []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", *waits, want)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Errorf("waits = %v, want %v", *waits, want)
+			break
+		}
+	}
+}
+
+func TestDeviceFlow_DefaultsWithoutIntervalAndExpiry(t *testing.T) {
+	tokenResponses := []string{
+		`{"error":"authorization_pending"}`,
+		`{"access_token":"gho_token","token_type":"bearer","scope":"gist"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login/device/code":
+			_, _ = w.Write([]byte(`{"device_code":"dev-code","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device"}`))
+		case "/login/oauth/access_token":
+			_, _ = w.Write([]byte(tokenResponses[0]))
+			tokenResponses = tokenResponses[1:]
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	f, waits := newTestDeviceFlow(t, srv)
+
+	code, err := f.RequestDeviceCode(context.Background(), []string{"gist"})
+	if err != nil {
+		t.Fatalf("RequestDeviceCode: %v", err)
+	}
+	if code.Interval != 5*time.Second || !code.ExpiresAt.IsZero() {
+		t.Errorf("expected a 5s interval and no deadline, got %+v", code)
+	}
+
+	// The code does not expire after the first poll
+	if token, err := f.PollDeviceToken(context.Background(), code); err != nil || token != "gho_token" {
+		t.Fatalf("PollDeviceToken = %q, %v", token, err)
+	}
+	if len(*waits) != 2 || (*waits)[0] != 5*time.Second || (*waits)[1] != 5*time.Second {
+		t.Errorf("waits = %v, want two of 5s", *waits)
+	}
+}
+
+func TestDeviceFlow_Denied(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error":"access_denied","error_description":"The user denied the request"}`))
+	}))
+	defer srv.Close()
+	f, _ := newTestDeviceFlow(t, srv)
+
+	_, err := f.PollDeviceToken(context.Background(), &domain.DeviceCode{DeviceCode: "dev", Interval: time.Second})
+	var flowErr domain.ErrDeviceFlow
+	if !errors.As(err, &flowErr) || flowErr.Code != "access_denied" {
+		t.Errorf("expected an access_denied ErrDeviceFlow, got %v", err)
+	}
+}
+
+func TestDeviceFlow_Expired(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+	}))
+	defer srv.Close()
+	f, _ := newTestDeviceFlow(t, srv)
+
+	code := &domain.DeviceCode{DeviceCode: "dev", ExpiresAt: time.Now().Add(-time.Second)}
+	_, err := f.PollDeviceToken(context.Background(), code)
+	var flowErr domain.ErrDeviceFlow
+	if !errors.As(err, &flowErr) || flowErr.Code != "expired_token" {
+		t.Errorf("expected an expired_token ErrDeviceFlow, got %v", err)
+	}
+}
+
+func TestDeviceFlow_Cancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+	}))
+	defer srv.Close()
+	f, _ := newTestDeviceFlow(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.PollDeviceToken(ctx, &domain.DeviceCode{DeviceCode: "dev"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters deriving the secrets file key from the passphrase
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	secretKeyLen = 32 // AES-256
+	saltLen      = 16
+)
+
+// EncryptedFileStore implements the SecretStore interface with a single
+// file encrypted with AES-GCM under a key derived from a passphrase. Every
+// token is kept in the one encrypted blob, so the file reveals neither the
+// tokens nor whose they are.
+type EncryptedFileStore struct {
+	fs         service.FileSystem
+	path       string
+	passphrase func() (string, error)
+}
+
+// secretsFile is the on-disk layout of the encrypted file. A fresh salt and
+// nonce are used for every write.
+type secretsFile struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewEncryptedFileStore creates an encrypted secret store at path. The
+// passphrase is asked for only when the file is read or written.
+func NewEncryptedFileStore(fs service.FileSystem, path string, passphrase func() (string, error)) *EncryptedFileStore {
+	return &EncryptedFileStore{fs: fs, path: path, passphrase: passphrase}
+}
+
+// EnvPassphrase reads the secrets file passphrase from GIST_PASSPHRASE
+func EnvPassphrase() (string, error) {
+	if passphrase := os.Getenv("GIST_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	return "", domain.ErrConfigMissing{Field: "GIST_PASSPHRASE"}
+}
+
+// Get returns the token of user on host
+func (s *EncryptedFileStore) Get(host, user string) (string, error) {
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := secrets[secretKey(host, user)]
+	if !ok {
+		return "", domain.ErrSecretNotFound{Key: secretKey(host, user)}
+	}
+	return token, nil
+}
+
+// Set stores the token of user on host
+func (s *EncryptedFileStore) Set(host, user, token string) error {
+	secrets, passphrase, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[secretKey(host, user)] = token
+	return s.save(secrets, passphrase)
+}
+
+// Delete removes the token of user on host
+func (s *EncryptedFileStore) Delete(host, user string) error {
+	if !s.fs.Exists(s.path) {
+		return nil
+	}
+
+	secrets, passphrase, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[secretKey(host, user)]; !ok {
+		return nil
+	}
+
+	delete(secrets, secretKey(host, user))
+	return s.save(secrets, passphrase)
+}
+
+// load decrypts the secrets file. A missing file holds no secrets; the
+// passphrase is still returned so a first save can use it.
+func (s *EncryptedFileStore) load() (map[string]string, string, error) {
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, "", err
+	}
+
+	secrets := make(map[string]string)
+	if !s.fs.Exists(s.path) {
+		return secrets, passphrase, nil
+	}
+
+	raw, err := s.fs.ReadFile(s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file secretsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, "", fmt.Errorf("parse %s: %w", s.path, err)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, "", errors.New("decrypt " + s.path + ": wrong passphrase or damaged file")
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, "", fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	return secrets, passphrase, nil
+}
+
+// save encrypts secrets with a fresh salt and nonce and writes the file
+func (s *EncryptedFileStore) save(secrets map[string]string, passphrase string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := secretsFile{Salt: make([]byte, saltLen)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	raw, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.fs.WriteFile(s.path, raw)
+}
+
+// newGCM derives the file key from passphrase and salt
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, secretKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretKey names the token of user on host
+func secretKey(host, user string) string {
+	return user + "@" + host
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func newTestSecretStore(t *testing.T, passphrase string) *EncryptedFileStore {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".gist-secrets")
+	return NewEncryptedFileStore(NewOSFileSystem(), path, func() (string, error) { return passphrase, nil })
+}
+
+func TestEncryptedFileStore_RoundTrip(t *testing.T) {
+	s := newTestSecretStore(t, "hunter2")
+
+	if err := s.Set("github.com", "me", "tok-me"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("github.com", "team", "tok-team"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		t.Fatalf("read secrets file: %v", err)
+	}
+	if strings.Contains(string(raw), "tok-me") || strings.Contains(string(raw), "team") {
+		t.Errorf("expected the secrets file to reveal nothing, got %s", raw)
+	}
+
+	token, err := s.Get("github.com", "me")
+	if err != nil || token != "tok-me" {
+		t.Errorf("Get = %q, %v; want tok-me", token, err)
+	}
+
+	if err := s.Delete("github.com", "me"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	var notFound domain.ErrSecretNotFound
+	if _, err := s.Get("github.com", "me"); !errors.As(err, &notFound) {
+		t.Errorf("expected ErrSecretNotFound after Delete, got %v", err)
+	}
+	if token, err := s.Get("github.com", "team"); err != nil || token != "tok-team" {
+		t.Errorf("expected the other token kept, got %q, %v", token, err)
+	}
+}
+
+func TestEncryptedFileStore_WrongPassphrase(t *testing.T) {
+	s := newTestSecretStore(t, "hunter2")
+	if err := s.Set("github.com", "me", "tok"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	wrong := NewEncryptedFileStore(s.fs, s.path, func() (string, error) { return "hunter3", nil })
+	if _, err := wrong.Get("github.com", "me"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+	if err := wrong.Set("github.com", "me", "other"); err == nil {
+		t.Error("expected Set with the wrong passphrase to fail")
+	}
+	if token, err := s.Get("github.com", "me"); err != nil || token != "tok" {
+		t.Errorf("expected the file unchanged, got %q, %v", token, err)
+	}
+}
+
+func TestEncryptedFileStore_MissingPassphrase(t *testing.T) {
+	t.Setenv("GIST_PASSPHRASE", "")
+	s := NewEncryptedFileStore(NewOSFileSystem(), filepath.Join(t.TempDir(), "s"), EnvPassphrase)
+
+	var missing domain.ErrConfigMissing
+	if err := s.Set("github.com", "me", "tok"); !errors.As(err, &missing) {
+		t.Errorf("expected ErrConfigMissing, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectSettingsFile is the name of a project's settings file, looked up
+// in the working directory and its parents
+const ProjectSettingsFile = ".gist.yaml"
+
+// SettingsFiles implements the SettingsRepository interface with YAML files:
+// the user's gist/config.yaml under the user config directory and a
+// project's .gist.yaml. Keys nest on their dots, so cache.ttl is written as
+// ttl under cache. The env layer is read from the keys' variables.
+type SettingsFiles struct {
+	fs          service.FileSystem
+	userPath    string
+	projectPath string
+}
+
+// NewSettingsFiles locates the settings files. The project file is the
+// nearest .gist.yaml from dir upwards, or a new one in dir.
+func NewSettingsFiles(fs service.FileSystem, dir string) (*SettingsFiles, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("determine config directory: %w", err)
+	}
+
+	return &SettingsFiles{
+		fs:          fs,
+		userPath:    filepath.Join(configDir, "gist", "config.yaml"),
+		projectPath: findProjectSettings(fs, dir),
+	}, nil
+}
+
+// Path returns the file of a layer
+func (s *SettingsFiles) Path(source string) (string, error) {
+	switch source {
+	case domain.SourceUser:
+		return s.userPath, nil
+	case domain.SourceProject:
+		return s.projectPath, nil
+	default:
+		return "", fmt.Errorf("%s settings are not kept in a file", source)
+	}
+}
+
+// Read returns the keys a layer sets, by dotted name. A missing file sets
+// nothing. Lists are joined with commas.
+func (s *SettingsFiles) Read(source string) (map[string]string, error) {
+	if source == domain.SourceEnv {
+		values := make(map[string]string)
+		for _, key := range domain.SettingKeys {
+			if value := os.Getenv(key.Env); value != "" {
+				values[key.Name] = value
+			}
+		}
+		return values, nil
+	}
+
+	path, err := s.Path(source)
+	if err != nil {
+		return nil, err
+	}
+	root, err := s.readNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	if len(root.Content) > 0 {
+		if err := flattenNode(root.Content[0], "", values); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	return values, nil
+}
+
+// Set writes key to a layer's file, keeping its other keys and comments
+func (s *SettingsFiles) Set(source, key, value string) error {
+	path, err := s.Path(source)
+	if err != nil {
+		return err
+	}
+	root, err := s.readNode(path)
+	if err != nil {
+		return err
+	}
+
+	if len(root.Content) == 0 {
+		root.Kind = yaml.DocumentNode
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if err := setNode(root.Content[0], strings.Split(key, "."), valueNode(key, value)); err != nil {
+		return fmt.Errorf("update %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return s.fs.WriteFile(path, buf.Bytes())
+}
+
+// readNode parses a settings file; a missing or empty file is an empty
+// document
+func (s *SettingsFiles) readNode(path string) (*yaml.Node, error) {
+	var root yaml.Node
+	if !s.fs.Exists(path) {
+		return &root, nil
+	}
+
+	raw, err := s.fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(root.Content) > 0 && root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parse %s: expected a mapping of settings", path)
+	}
+	return &root, nil
+}
+
+// flattenNode collects the leaves of a mapping under their dotted names
+func flattenNode(node *yaml.Node, prefix string, values map[string]string) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping", prefix)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		value := node.Content[i+1]
+		switch value.Kind {
+		case yaml.MappingNode:
+			if err := flattenNode(value, name, values); err != nil {
+				return err
+			}
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("%s: expected a list of values", name)
+				}
+				items = append(items, item.Value)
+			}
+			values[name] = strings.Join(items, ", ")
+		case yaml.ScalarNode:
+			values[name] = value.Value
+		default:
+			return fmt.Errorf("%s: unsupported value", name)
+		}
+	}
+	return nil
+}
+
+// setNode sets the value at path in a mapping, creating nested mappings
+func setNode(node *yaml.Node, path []string, value *yaml.Node) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			node.Content[i+1] = value
+			return nil
+		}
+		if node.Content[i+1].Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", path[0])
+		}
+		return setNode(node.Content[i+1], path[1:], value)
+	}
+
+	child := value
+	if len(path) > 1 {
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		if err := setNode(child, path[1:], value); err != nil {
+			return err
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}, child)
+	return nil
+}
+
+// valueNode encodes a value for the file; tags are written as a list
+func valueNode(key, value string) *yaml.Node {
+	if key == "defaults.tags" {
+		tags, _ := domain.ParseTagList(value)
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, tag := range tags {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: tag})
+		}
+		return node
+	}
+
+	node := &yaml.Node{}
+	node.SetString(value)
+	return node
+}
+
+// findProjectSettings returns the nearest project settings file from dir
+// upwards, or the path of a new one in dir
+func findProjectSettings(fs service.FileSystem, dir string) string {
+	for current := dir; ; {
+		path := filepath.Join(current, ProjectSettingsFile)
+		if fs.Exists(path) {
+			return path
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.Join(dir, ProjectSettingsFile)
+		}
+		current = parent
+	}
+}
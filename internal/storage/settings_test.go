@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func TestSettingsFiles_ReadAndSet(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "config.yaml")
+	content := `# my settings
+output: json
+cache:
+  ttl: 10m # keep it short
+defaults:
+  tags: [blog, go]
+`
+	if err := os.WriteFile(userPath, []byte(content), 0600); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	s := &SettingsFiles{fs: NewOSFileSystem(), userPath: userPath, projectPath: filepath.Join(dir, ProjectSettingsFile)}
+
+	values, err := s.Read(domain.SourceUser)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if values["output"] != "json" || values["cache.ttl"] != "10m" || values["defaults.tags"] != "blog, go" {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	if err := s.Set(domain.SourceUser, "cache.dir", "/tmp/gist-cache"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set(domain.SourceUser, "defaults.tags", "notes,#til"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set(domain.SourceUser, "defaults.visibility", "public"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	raw, _ := os.ReadFile(userPath)
+	for _, want := range []string{"# my settings", "# keep it short", "dir: /tmp/gist-cache", "tags: [notes, til]", "visibility: public"} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("expected %q in the rewritten file:\n%s", want, raw)
+		}
+	}
+
+	values, _ = s.Read(domain.SourceUser)
+	if values["cache.dir"] != "/tmp/gist-cache" || values["cache.ttl"] != "10m" || values["defaults.tags"] != "notes, til" {
+		t.Errorf("unexpected values after Set %v", values)
+	}
+
+	// A project file that does not exist yet sets nothing and is created on Set
+	if values, err := s.Read(domain.SourceProject); err != nil || len(values) != 0 {
+		t.Errorf("expected no project settings, got %v err=%v", values, err)
+	}
+	if err := s.Set(domain.SourceProject, "output", "table"); err != nil {
+		t.Fatalf("Set project: %v", err)
+	}
+	if values, _ := s.Read(domain.SourceProject); values["output"] != "table" {
+		t.Errorf("expected the project file written, got %v", values)
+	}
+}
+
+func TestSettingsFiles_RejectsMalformed(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(userPath, []byte("- just\n- a list\n"), 0600); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	s := &SettingsFiles{fs: NewOSFileSystem(), userPath: userPath}
+
+	if _, err := s.Read(domain.SourceUser); err == nil {
+		t.Error("expected an error for a file that is not a mapping")
+	}
+	if err := s.Set(domain.SourceUser, "output", "json"); err == nil {
+		t.Error("expected Set to refuse rewriting a malformed file")
+	}
+}
+
+func TestSettingsFiles_EnvLayer(t *testing.T) {
+	t.Setenv("GIST_OUTPUT", "json")
+	t.Setenv("GIST_CACHE_TTL", "")
+	s := &SettingsFiles{fs: NewOSFileSystem()}
+
+	values, err := s.Read(domain.SourceEnv)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if values["output"] != "json" {
+		t.Errorf("expected GIST_OUTPUT as output, got %v", values)
+	}
+	if _, ok := values["cache.ttl"]; ok {
+		t.Errorf("expected unset variables skipped, got %v", values)
+	}
+}
+
+func TestFindProjectSettings(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "posts", "2024")
+	if err := os.MkdirAll(nested, 0700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	fs := NewOSFileSystem()
+
+	if got := findProjectSettings(fs, nested); got != filepath.Join(nested, ProjectSettingsFile) {
+		t.Errorf("expected a new file in the working directory, got %s", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ProjectSettingsFile), []byte("output: json\n"), 0600); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	if got := findProjectSettings(fs, nested); got != filepath.Join(root, ProjectSettingsFile) {
+		t.Errorf("expected the parent's file, got %s", got)
+	}
+}
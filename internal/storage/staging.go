@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+)
+
+// StagingIndex implements the StagingRepository interface using a JSON file
+type StagingIndex struct {
+	fs        service.FileSystem
+	indexPath string
+}
+
+// NewStagingIndex creates a staging index stored at indexPath
+func NewStagingIndex(fs service.FileSystem, indexPath string) *StagingIndex {
+	return &StagingIndex{
+		fs:        fs,
+		indexPath: indexPath,
+	}
+}
+
+// DefaultStagingPath returns the staging index location under the user's
+// config directory
+func DefaultStagingPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gist", "index.json"), nil
+}
+
+// Load retrieves the staged files; an absent index is empty
+func (s *StagingIndex) Load() ([]domain.StagedFile, error) {
+	if !s.fs.Exists(s.indexPath) {
+		return nil, nil
+	}
+
+	data, err := s.fs.ReadFile(s.indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []domain.StagedFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Save persists the staged files
+func (s *StagingIndex) Save(files []domain.StagedFile) error {
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return s.fs.WriteFile(s.indexPath, data)
+}
+
+// Clear removes the staging index
+func (s *StagingIndex) Clear() error {
+	return s.fs.RemoveAll(s.indexPath)
+}
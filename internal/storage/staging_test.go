@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gist/internal/domain"
+)
+
+func TestStagingIndex_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gist", "index.json")
+	idx := NewStagingIndex(NewOSFileSystem(), path)
+
+	files, err := idx.Load()
+	if err != nil || len(files) != 0 {
+		t.Fatalf("missing index should load empty, got %v err=%v", files, err)
+	}
+
+	want := []domain.StagedFile{{Path: "/work/a.md", Filename: "a.md", Content: "x", Hash: "h"}}
+	if err := idx.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	files, err = idx.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "/work/a.md" || files[0].Content != "x" {
+		t.Errorf("unexpected staged files: %+v", files)
+	}
+
+	if err := idx.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	files, err = idx.Load()
+	if err != nil || len(files) != 0 {
+		t.Errorf("cleared index should load empty, got %v err=%v", files, err)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"gist/internal/domain"
+	"gist/internal/service"
+)
+
+// WorkingCopyStore implements the WorkingCopyRepository interface by keeping
+// a JSON metadata file inside each working copy directory
+type WorkingCopyStore struct {
+	fs service.FileSystem
+}
+
+// NewWorkingCopyStore creates a new working copy metadata store
+func NewWorkingCopyStore(fs service.FileSystem) *WorkingCopyStore {
+	return &WorkingCopyStore{fs: fs}
+}
+
+// Load reads the metadata of the working copy in dir
+func (s *WorkingCopyStore) Load(dir string) (*domain.WorkingCopy, error) {
+	path := filepath.Join(dir, domain.WorkingCopyFile)
+	if !s.fs.Exists(path) {
+		return nil, domain.ErrNotWorkingCopy{Dir: dir}
+	}
+
+	data, err := s.fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var wc domain.WorkingCopy
+	if err := json.Unmarshal(data, &wc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if wc.Files == nil {
+		wc.Files = make(map[string]string)
+	}
+
+	return &wc, nil
+}
+
+// Save writes the metadata of the working copy in dir
+func (s *WorkingCopyStore) Save(dir string, wc *domain.WorkingCopy) error {
+	data, err := json.MarshalIndent(wc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return s.fs.WriteFile(filepath.Join(dir, domain.WorkingCopyFile), data)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gist/internal/domain"
+)
+
+func TestWorkingCopyStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewWorkingCopyStore(NewOSFileSystem())
+
+	_, err := store.Load(dir)
+	var notWC domain.ErrNotWorkingCopy
+	if !errors.As(err, &notWC) {
+		t.Fatalf("expected domain.ErrNotWorkingCopy for a plain dir, got %v", err)
+	}
+
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &domain.WorkingCopy{ID: "g1", UpdatedAt: updated, Files: map[string]string{"a.md": "h"}}
+	if err := store.Save(dir, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := store.Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.ID != "g1" || !got.UpdatedAt.Equal(updated) || got.Files["a.md"] != "h" {
+		t.Errorf("unexpected working copy: %+v", got)
+	}
+}
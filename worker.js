@@ -89,9 +89,11 @@ class CacheService {
 }
 
 class GitHubService {
-  constructor(githubUser, githubToken) {
+  constructor(githubUser, githubToken, apiBaseUrl) {
     this.githubUser = githubUser;
     this.githubToken = githubToken;
+    // GitHub Enterprise Server serves the API under /api/v3
+    this.apiBaseUrl = (apiBaseUrl || CONFIG.API_BASE_URL).replace(/\/+$/, "");
     this.activeRequests = 0;
     this.maxConcurrent = 10; // Max 10 concurrent requests
     this.requestQueue = [];
@@ -132,7 +134,7 @@ class GitHubService {
 
     while (hasMore) {
       const response = await this.fetchWithLimit(
-        `${CONFIG.API_BASE_URL}/users/${this.githubUser}/gists?per_page=100&page=${page}`,
+        `${this.apiBaseUrl}/users/${this.githubUser}/gists?per_page=100&page=${page}`,
         { headers },
       );
 
@@ -162,7 +164,7 @@ class GitHubService {
   async fetchGistDetails(id) {
     const headers = this.getHeaders();
     const response = await this.fetchWithLimit(
-      `${CONFIG.API_BASE_URL}/gists/${id}`,
+      `${this.apiBaseUrl}/gists/${id}`,
       { headers },
     );
 
@@ -355,7 +357,11 @@ class GistBlog {
     this.siteName = env.SITE_NAME || CONFIG.DEFAULT_SITE_NAME;
     this.metricsService = new MetricsService();
     this.cacheService = new CacheService(env, this.metricsService);
-    this.githubService = new GitHubService(githubUser, githubToken);
+    this.githubService = new GitHubService(
+      githubUser,
+      githubToken,
+      env.GITHUB_API_URL,
+    );
     this.markdownParser = new MarkdownParser();
   }
 